@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"lem-in/colony"
+	"lem-in/datas"
+	"lem-in/modules"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the outcome of one solver on one map.
+type Result struct {
+	Map      string
+	Solver   string
+	Turns    int64
+	Duration time.Duration
+	Allocs   int64 // Heap allocations during the resolution, -1 when unknown (external executables)
+	Err      error
+}
+
+// benchMap is a parsed map ready to be solved.
+type benchMap struct {
+	name  string
+	path  string
+	ants  int64
+	rooms []*modules.Room
+}
+
+// main runs every selected solver on every map of a directory and prints the comparison.
+func main() {
+	dir := flag.String("dir", "files", "directory containing the maps to solve (every .txt file)")
+	format := flag.String("format", "table", "output format: table, csv or md")
+	solverList := flag.String("solvers", strings.Join(colony.SolverNames(), ","), "comma-separated in-process solvers to run (empty for none)")
+	timeout := flag.Duration("timeout", 10*time.Second, "time limit for each run")
+	basename := flag.Bool("basename", false, "pass only the map file name to external executables (for lem-in, which reads maps from files/)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: ./lem-in-bench [options] [executable ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	maps, err := loadMaps(*dir)
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		os.Exit(1)
+	}
+
+	var solverNames []string
+	if *solverList != "" {
+		solverNames = strings.Split(*solverList, ",")
+	}
+	var results []Result
+	for _, m := range maps {
+		for _, name := range solverNames {
+			results = append(results, runSolver(m, name, *timeout))
+		}
+		for _, executable := range flag.Args() {
+			arg := m.path
+			if *basename {
+				arg = filepath.Base(m.path)
+			}
+			results = append(results, runExecutable(m, executable, arg, *timeout))
+		}
+	}
+
+	switch *format {
+	case "csv":
+		err = writeCSV(os.Stdout, results)
+	case "md":
+		err = writeMarkdown(os.Stdout, results)
+	case "table":
+		err = writeTable(os.Stdout, results)
+	default:
+		err = errors.New("Unknown format : " + *format)
+	}
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		os.Exit(1)
+	}
+}
+
+// loadMaps parses every valid map of the directory. Invalid maps are reported on stderr and skipped.
+func loadMaps(dir string) ([]benchMap, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("No map found in " + dir)
+	}
+	sort.Strings(paths)
+
+	var maps []benchMap
+	for _, path := range paths {
+		lines, err := datas.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s : %v\n", path, err)
+			continue
+		}
+		filedatas := datas.SaveDatas(lines)
+		datas.CheckErrors(&filedatas)
+		if len(filedatas.Errors) != 0 {
+			fmt.Fprintf(os.Stderr, "skipping %s : %v\n", path, filedatas.Errors[0])
+			continue
+		}
+		rooms := colony.CreatRooms(filedatas)
+		colony.CreatColony(filedatas, rooms)
+		maps = append(maps, benchMap{name: filepath.Base(path), path: path, ants: filedatas.NbAnts, rooms: rooms})
+	}
+	return maps, nil
+}
+
+// runSolver solves a map in-process and checks the produced moves.
+func runSolver(m benchMap, name string, timeout time.Duration) Result {
+	result := Result{Map: m.name, Solver: name, Allocs: -1}
+	solver, err := colony.NewSolver(name, colony.Options{})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	solution, err := solver.Solve(ctx, m.rooms, m.ants)
+	result.Duration = time.Since(start)
+	runtime.ReadMemStats(&after)
+	result.Allocs = int64(after.Mallocs - before.Mallocs)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if !solution.Optimal {
+		result.Err = errors.New("timeout (best-effort result)")
+	}
+
+	var moves bytes.Buffer
+	if err := colony.WriteSolution(&moves, solution); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Turns, err = colony.CheckMoves(m.ants, m.rooms, strings.Split(moves.String(), "\n"))
+	if err != nil {
+		result.Err = err
+	}
+	return result
+}
+
+// runExecutable runs an external lem-in executable on a map and checks the moves it prints.
+func runExecutable(m benchMap, executable, arg string, timeout time.Duration) Result {
+	result := Result{Map: m.name, Solver: executable, Allocs: -1}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := exec.CommandContext(ctx, executable, arg).Output()
+	result.Duration = time.Since(start)
+	if ctx.Err() != nil {
+		result.Err = errors.New("timeout")
+		return result
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Turns, result.Err = colony.CheckMoves(m.ants, m.rooms, extractMoves(output))
+	return result
+}
+
+// extractMoves returns the block of move lines (lines starting with "L") of a lem-in output.
+func extractMoves(output []byte) []string {
+	var moves []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "L") {
+			moves = append(moves, line)
+		} else if len(moves) > 0 {
+			break
+		}
+	}
+	return moves
+}
+
+// row formats a result as table cells.
+func (r Result) row() []string {
+	turns, allocs, status := "-", "-", "ok"
+	if r.Err != nil {
+		status = r.Err.Error()
+	}
+	if r.Turns > 0 {
+		turns = strconv.FormatInt(r.Turns, 10)
+	}
+	if r.Allocs >= 0 {
+		allocs = strconv.FormatInt(r.Allocs, 10)
+	}
+	return []string{r.Map, r.Solver, turns, r.Duration.Round(time.Microsecond).String(), allocs, status}
+}
+
+var header = []string{"map", "solver", "turns", "time", "allocs", "status"}
+
+// summary aggregates the results of each solver: total turns of the successful runs, total time and failures.
+func summary(results []Result) [][]string {
+	type total struct {
+		turns    int64
+		duration time.Duration
+		failures int
+	}
+	totals := make(map[string]*total)
+	var order []string
+	for _, r := range results {
+		t, exists := totals[r.Solver]
+		if !exists {
+			t = &total{}
+			totals[r.Solver] = t
+			order = append(order, r.Solver)
+		}
+		t.duration += r.Duration
+		if r.Err != nil {
+			t.failures++
+		} else {
+			t.turns += r.Turns
+		}
+	}
+	var rows [][]string
+	for _, name := range order {
+		t := totals[name]
+		rows = append(rows, []string{name, strconv.FormatInt(t.turns, 10), t.duration.Round(time.Microsecond).String(), strconv.Itoa(t.failures)})
+	}
+	return rows
+}
+
+var summaryHeader = []string{"solver", "total turns", "total time", "failures"}
+
+func writeTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, r := range results {
+		fmt.Fprintln(tw, strings.Join(r.row(), "\t"))
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, strings.Join(summaryHeader, "\t"))
+	for _, row := range summary(results) {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, r := range results {
+		cw.Write(r.row())
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeMarkdown(w io.Writer, results []Result) error {
+	var rows [][]string
+	for _, r := range results {
+		rows = append(rows, r.row())
+	}
+	writer := bufio.NewWriter(w)
+	markdownTable(writer, header, rows)
+	fmt.Fprintln(writer)
+	markdownTable(writer, summaryHeader, summary(results))
+	return writer.Flush()
+}
+
+// markdownTable writes a header and rows as a Markdown table, escaping pipes in cells.
+func markdownTable(w io.Writer, header []string, rows [][]string) {
+	line := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, cell := range cells {
+			escaped[i] = strings.ReplaceAll(cell, "|", "\\|")
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | "))
+	}
+	line(header)
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	line(separator)
+	for _, row := range rows {
+		line(row)
+	}
+}
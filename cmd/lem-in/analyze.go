@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"lem-in/colony"
+	"lem-in/modules"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+// analyze runs the "analyze" subcommand, which studies a colony instead of printing its solution.
+func analyze(args []string) {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	critical := flags.Bool("critical", false, "remove each tunnel and room in turn and rank them by their effect on the optimal number of turns")
+	top := flags.Int("top", 10, "number of elements listed by --critical (0 lists all of them)")
+	sweep := flags.Bool("sweep", false, "compute the optimal number of turns and path combination for a range of ant counts")
+	from := flags.Int64("from", 1, "first ant count of --sweep")
+	to := flags.Int64("to", 0, "last ant count of --sweep (0 uses the ant count of the map)")
+	step := flags.Int64("step", 1, "ant count increment of --sweep")
+	format := flags.String("format", "plot", "output format of --sweep: plot or csv")
+	within := flags.Int64("within", -1, "find the maximum number of ants that reach the end within this many turns, and their moves")
+	advise := flags.Int64("advise", -1, "suggest new tunnels between existing rooms so that the ants of the map need at most this many turns")
+	maxDistance := flags.Float64("max-distance", 0, "longest tunnel suggested by --advise, in coordinate units (0 for no limit)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: ./lem-in analyze [options] filename")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return
+	}
+	if !*critical && !*sweep && *within < 0 && *advise < 0 {
+		fmt.Println("Error : nothing to analyze, use --critical, --sweep, --within or --advise")
+		return
+	}
+	if *format != "plot" && *format != "csv" {
+		fmt.Println(fmt.Errorf("error : %w", errors.New("Unknown format : "+*format)))
+		return
+	}
+	_, filedatas, rooms, ok := loadColony(flags.Arg(0))
+	if !ok {
+		return
+	}
+	if *critical {
+		printCritical(filedatas.NbAnts, rooms, *top)
+	}
+	if *sweep {
+		if *to == 0 {
+			*to = filedatas.NbAnts
+		}
+		// Every ant count is solved separately, so a range of millions of counts would not finish
+		if *from >= 1 && *to >= *from && *step >= 1 && (*to-*from)/(*step) >= maxSweepPoints {
+			fmt.Println(fmt.Errorf("error : %w", fmt.Errorf("Too many ant counts for --sweep : %d to %d, use --to or --step to compute at most %d of them", *from, *to, maxSweepPoints)))
+		} else {
+			printSweep(rooms, *from, *to, *step, *format)
+		}
+	}
+	if *within >= 0 {
+		printMaxAnts(rooms, *within)
+	}
+	if *advise >= 0 {
+		printAdvice(filedatas.NbAnts, rooms, *advise, *maxDistance)
+	}
+}
+
+// printAdvice prints the tunnels to dig to reach the target number of turns, with the turns before and after.
+func printAdvice(ants int64, rooms []*modules.Room, target int64, maxDistance float64) {
+	advice := colony.AdviseLinks(rooms, ants, target, maxDistance, colony.Options{})
+	fmt.Printf("Turns before : %s, target : %d\n", turnsString(advice.Before), target)
+	if advice.Skipped > 0 {
+		fmt.Printf("Only the shortest tunnels were tried, %d longer ones skipped (use --max-distance to choose)\n", advice.Skipped)
+	}
+	if len(advice.Links) == 0 && advice.Reached {
+		fmt.Println("The colony already reaches the target")
+		return
+	}
+	fmt.Printf("Suggested tunnels (%d) :\n", len(advice.Links))
+	for _, link := range advice.Links {
+		fmt.Printf("  %s-%s (distance %.2f)\n", link.From, link.To, link.Distance)
+	}
+	fmt.Printf("Turns after : %s\n", turnsString(advice.After))
+	if !advice.Reached {
+		fmt.Println("error : the target cannot be reached with new tunnels between existing rooms")
+	}
+}
+
+// turnsString formats a number of turns, 0 meaning that the end cannot be reached.
+func turnsString(turns int64) string {
+	if turns == 0 {
+		return "unreachable"
+	}
+	return strconv.FormatInt(turns, 10)
+}
+
+// printMaxAnts prints the maximum number of ants that reach the end within the given number of turns, and their moves.
+func printMaxAnts(rooms []*modules.Room, turns int64) {
+	ants, solution, err := colony.MaxAnts(context.Background(), rooms, turns, colony.Options{})
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+	fmt.Printf("Maximum ants within %d turns : %d\n\n", turns, ants)
+	colony.PrintSolution(solution)
+	fmt.Println("--------------------")
+	fmt.Printf("Turns : %d, sum of arrival turns : %d, total moves : %d\n", solution.Turns, solution.SumArrival, solution.TotalMoves)
+}
+
+// printCritical prints the most critical tunnels and rooms of the colony, most critical first.
+func printCritical(ants int64, rooms []*modules.Room, top int) {
+	elements, turns, err := colony.CriticalElements(rooms, ants, colony.Options{})
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+	fmt.Printf("Critical elements (%d ants, %d turns with the complete colony)\n", ants, turns)
+	if top > 0 && top < len(elements) {
+		elements = elements[:top]
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rank\telement\tkind\tturns\tincrease\tcut")
+	for i, element := range elements {
+		turns, increase, cut := strconv.FormatInt(element.Turns, 10), "+"+strconv.FormatInt(element.Increase, 10), "-"
+		if element.Unreachable {
+			turns, increase = "unreachable", "-"
+		}
+		if element.Cut && element.Kind == "room" {
+			cut = "articulation point"
+		} else if element.Cut {
+			cut = "bridge"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", i+1, element.Name, element.Kind, turns, increase, cut)
+	}
+	tw.Flush()
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"lem-in/colony"
 	"lem-in/datas"
+	"lem-in/layout"
+	"lem-in/modules"
+	"lem-in/render"
 	"os"
 	"strings"
 	"time"
@@ -13,36 +19,132 @@ import (
 func main() {
 	// Check for correct usage
 	start := time.Now()
-	if len(os.Args) != 2 && len(os.Args) != 3 {
-		fmt.Println("Error : Usage is './lem-in filename' or './lem-in filename | ./visualizer")
+	timeout := flag.Duration("timeout", 0, "stop the resolution after this duration (e.g. 500ms, 2s) and keep the best result found")
+	workers := flag.Int("workers", 0, "number of goroutines evaluating path combinations (0 uses every CPU)")
+	solverName := flag.String("solver", "bruteforce", "resolution strategy ("+strings.Join(colony.SolverNames(), ", ")+")")
+	objectiveStr := flag.String("objective", "makespan", "comma-separated criteria compared in order: makespan (last arrival turn), arrival (sum of arrival turns), moves (total moves)")
+	maxGap := flag.Int64("fail-if-gap-above", -1, "exit with status 1 when the number of turns exceeds the lower bound by more than this many turns (-1 disables the check)")
+	renderFile := flag.String("render", "", "also write the animation of the solution: .gif, .png or .apng (animated PNG), .svg (still image), or a frame pattern like frames/%04d.png")
+	renderSize := flag.String("render-size", fmt.Sprintf("%dx%d", render.DefaultOptions.Width, render.DefaultOptions.Height), "size of the rendered images, in pixels")
+	renderFPS := flag.Int("render-fps", render.DefaultOptions.FPS, "frames per second of the rendered animation")
+	renderSpeed := flag.Float64("render-speed", render.DefaultOptions.Speed, "turns per second of the rendered animation")
+	renderLayout := flag.String("render-layout", render.DefaultOptions.Layout, "room layout of the rendered animation ("+strings.Join(layout.Names(), ", ")+")")
+	animatedSVG := flag.Bool("render-animated-svg", false, "animate the ants turn by turn in an .svg file (SMIL) instead of a still image")
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		analyze(os.Args[2:])
 		return
 	}
-	filename := os.Args[1]
-	// Read and parse the input file
-	rawdatas := datas.GetDatas(filename)
-	instructions := strings.Join(rawdatas, "\n")
-	filedatas := datas.SaveDatas(rawdatas)
-	// Run error checks
-	datas.CheckErrors(&filedatas)
-	if len(filedatas.Errors) != 0 {
-		for _, err := range filedatas.Errors {
-			fmt.Println(fmt.Errorf("error : %w", err))
-		}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Error : Usage is './lem-in [options] filename', './lem-in [options] filename | ./visualizer' or './lem-in analyze [options] filename'")
 		return
 	}
-	// Build the rooms and colony structure
-	rooms := colony.CreatRooms(filedatas)
-	colony.CreatColony(filedatas, rooms)
+	filename := flag.Arg(0)
+	objective, err := colony.ParseObjective(*objectiveStr)
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+	solver, err := colony.NewSolver(*solverName, colony.Options{Workers: *workers, Objective: objective})
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+	rawdatas, filedatas, rooms, ok := loadColony(filename)
+	if !ok {
+		return
+	}
+	instructions := strings.Join(rawdatas, "\n")
 	// Find and print the best solution
 	durationColony := time.Since(start)
 	startAlgo := time.Now()
-	bestset := colony.Resolve(filedatas.NbAnts, rooms)
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	solution, err := solver.Solve(ctx, rooms, filedatas.NbAnts)
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+	// The bounds share the timeout of the resolution: when it is reached, the solution is printed without them
+	bounds, err := colony.LowerBounds(ctx, rooms, filedatas.NbAnts)
+	boundsComputed := err == nil
+	if err != nil && ctx.Err() == nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
 	fmt.Println(instructions + "\n")
-	colony.PrintResolve(filedatas.NbAnts, bestset)
+	colony.PrintSolution(solution)
 	durationAll := time.Since(start)
 	durationAlgo := time.Since(startAlgo)
 	fmt.Println("--------------------")
 	fmt.Printf("Colony constructed in %s\n", durationColony)
 	fmt.Printf("Algo resolution done in %s\n", durationAlgo)
 	fmt.Printf("Total resolution done in %s\n", durationAll)
+	// Only the exact solver compares every move schedule, the others every schedule along independent paths
+	_, exact := solver.(colony.Exact)
+	switch {
+	case !solution.Optimal:
+		fmt.Println("Result : best-effort (timeout reached before the end of the search)")
+	case exact:
+		fmt.Println("Result : proven optimal (search completed)")
+	default:
+		fmt.Println("Result : search completed (optimal among independent paths)")
+	}
+	fmt.Printf("Objective : %s, makespan : %d turns, sum of arrival turns : %d, total moves : %d\n",
+		objective, solution.Turns, solution.SumArrival, solution.TotalMoves)
+	gap := solution.Turns - bounds.Best()
+	if boundsComputed {
+		fmt.Printf("Turns : %d, lower bound : %d (shortest path bound %d, flow bound %d), gap : %d\n",
+			solution.Turns, bounds.Best(), bounds.Simple, bounds.Flow, gap)
+	} else {
+		fmt.Printf("Turns : %d, lower bound : not computed (timeout reached)\n", solution.Turns)
+	}
+	if *renderFile != "" {
+		opts := render.Options{FPS: *renderFPS, Speed: *renderSpeed, Layout: *renderLayout, AnimatedSVG: *animatedSVG}
+		if _, err := fmt.Sscanf(*renderSize, "%dx%d", &opts.Width, &opts.Height); err != nil {
+			fmt.Println(fmt.Errorf("error : %w", errors.New("Bad image size : "+*renderSize)))
+			return
+		}
+		animation, err := render.NewAnimation(rooms, colony.SolutionMoves(solution), opts)
+		if err == nil {
+			err = render.WriteFile(*renderFile, animation)
+		}
+		if err != nil {
+			fmt.Println(fmt.Errorf("error : %w", err))
+			return
+		}
+		fmt.Printf("Animation written to %s\n", *renderFile)
+	}
+	if *maxGap >= 0 && !boundsComputed {
+		fmt.Println("error : the gap cannot be checked without the lower bound")
+		os.Exit(1)
+	}
+	if *maxGap >= 0 && gap > *maxGap {
+		fmt.Printf("error : gap of %d turns above the allowed %d\n", gap, *maxGap)
+		os.Exit(1)
+	}
+}
+
+// loadColony reads and checks a map of the files/ directory and builds its colony.
+// Errors are printed and reported with ok set to false.
+func loadColony(filename string) (rawdatas []string, filedatas modules.Datas, rooms []*modules.Room, ok bool) {
+	// Read and parse the input file
+	rawdatas = datas.GetDatas(filename)
+	filedatas = datas.SaveDatas(rawdatas)
+	// Run error checks
+	datas.CheckErrors(&filedatas)
+	if len(filedatas.Errors) != 0 {
+		for _, err := range filedatas.Errors {
+			fmt.Println(fmt.Errorf("error : %w", err))
+		}
+		return nil, filedatas, nil, false
+	}
+	// Build the rooms and colony structure
+	rooms = colony.CreatRooms(filedatas)
+	colony.CreatColony(filedatas, rooms)
+	return rawdatas, filedatas, rooms, true
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"lem-in/colony"
+	"lem-in/modules"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Size of the ASCII plot of --sweep
+const (
+	plotWidth  = 72
+	plotHeight = 20
+)
+
+// Largest number of ant counts computed by --sweep
+const maxSweepPoints = 100000
+
+// printSweep prints the optimal number of turns and path combination for each ant count, as CSV or as an ASCII plot.
+func printSweep(rooms []*modules.Room, from, to, step int64, format string) {
+	points, err := colony.Sweep(context.Background(), rooms, from, to, step, colony.Options{})
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+	if format == "csv" {
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"ants", "turns", "paths", "breakpoint", "set"})
+		for _, point := range points {
+			writer.Write([]string{strconv.FormatInt(point.Ants, 10), strconv.FormatInt(point.Turns, 10),
+				strconv.Itoa(len(point.Paths)), strconv.FormatBool(point.Breakpoint), pathSetString(point.Paths)})
+		}
+		writer.Flush()
+		return
+	}
+	plotSweep(points)
+	fmt.Println()
+	fmt.Printf("Ants >= %d : %d paths (%s)\n", points[0].Ants, len(points[0].Paths), pathSetString(points[0].Paths))
+	for _, point := range points[1:] {
+		if point.Breakpoint {
+			fmt.Printf("Ants >= %d : %d paths (%s)\n", point.Ants, len(point.Paths), pathSetString(point.Paths))
+		}
+	}
+}
+
+// plotSweep draws the number of turns against the number of ants. Each column shows one ant count, or the
+// largest number of turns of a group of ant counts when there are more points than columns.
+// Columns where the best path combination changes are drawn with 'o' instead of '*'.
+func plotSweep(points []colony.SweepPoint) {
+	columns := min(len(points), plotWidth)
+	turns := make([]int64, columns)
+	breakpoints := make([]bool, columns)
+	for i, point := range points {
+		column := i * columns / len(points)
+		turns[column] = max(turns[column], point.Turns)
+		breakpoints[column] = breakpoints[column] || point.Breakpoint
+	}
+	lowest, highest := turns[0], turns[0]
+	for _, t := range turns {
+		lowest, highest = min(lowest, t), max(highest, t)
+	}
+	rows := int64(plotHeight)
+	if highest-lowest+1 < rows {
+		rows = highest - lowest + 1
+	}
+	// Row of a number of turns, 0 being the top of the plot
+	row := func(t int64) int64 {
+		if highest == lowest {
+			return 0
+		}
+		return (highest - t) * (rows - 1) / (highest - lowest)
+	}
+	label := len(strconv.FormatInt(highest, 10))
+	fmt.Println("turns")
+	for r := int64(0); r < rows; r++ {
+		axis := strings.Repeat(" ", label)
+		if r == 0 {
+			axis = fmt.Sprintf("%*d", label, highest)
+		} else if r == rows-1 {
+			axis = fmt.Sprintf("%*d", label, lowest)
+		}
+		line := []byte(strings.Repeat(" ", columns))
+		for column, t := range turns {
+			if row(t) == r {
+				line[column] = '*'
+				if breakpoints[column] {
+					line[column] = 'o'
+				}
+			}
+		}
+		fmt.Printf("%s |%s\n", axis, strings.TrimRight(string(line), " "))
+	}
+	fmt.Printf("%s +%s\n", strings.Repeat(" ", label), strings.Repeat("-", columns))
+	first, last := strconv.FormatInt(points[0].Ants, 10), strconv.FormatInt(points[len(points)-1].Ants, 10)
+	fmt.Printf("%s  %s%*s ants\n", strings.Repeat(" ", label), first, max(columns-len(first), len(last)+1), last)
+}
+
+// pathSetString writes a path combination as "start-a-end start-b-end".
+func pathSetString(paths [][]*modules.Room) string {
+	var names []string
+	for _, path := range paths {
+		var rooms []string
+		for _, room := range path {
+			rooms = append(rooms, room.Name)
+		}
+		names = append(names, strings.Join(rooms, "-"))
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"lem-in/modules"
+	"lem-in/render"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// track lists the moves of one ant: the index of the turn of each move and the room it reaches.
+type track struct {
+	id string
+	render.Track
+}
+
+// buildTracks returns the track of every ant, in the order of ants.
+func buildTracks(turns [][]*modules.Ant, ants []*modules.Ant) []*track {
+	tracks := make([]*track, len(ants))
+	byId := make(map[string]*track, len(ants))
+	for i, ant := range ants {
+		tracks[i] = &track{id: ant.Id}
+		byId[ant.Id] = tracks[i]
+	}
+	for turn, moves := range turns {
+		for _, ant := range moves {
+			t := byId[ant.Id]
+			t.Turns = append(t.Turns, turn)
+			t.Rooms = append(t.Rooms, ant.CurrentRoom)
+		}
+	}
+	return tracks
+}
+
+// antState is the position of an ant at the current playback position.
+type antState struct {
+	id       string
+	from, to *modules.Room
+}
+
+// moving reports whether the ant is in a tunnel.
+func (s antState) moving() bool {
+	return s.from != s.to
+}
+
+// census counts the ants waiting in the start room, in the colony and arrived, and the ants standing in each room.
+type census struct {
+	waiting, inTransit, arrived int
+	occupancy                   map[*modules.Room]int
+}
+
+// antStates returns the position of every ant at the current playback position and the census of the colony.
+// At the very end of the animation, the ants of the last turn have reached their rooms.
+func (g *Visualization) antStates() ([]antState, census, float64) {
+	turn, progress := g.Playback.Current()
+	start, end := g.Rooms[0], g.Rooms[len(g.Rooms)-1]
+	states := make([]antState, len(g.Tracks))
+	count := census{occupancy: make(map[*modules.Room]int)}
+	for i, t := range g.Tracks {
+		from, to := t.At(turn, start)
+		if progress >= 1 {
+			from = to
+		}
+		states[i] = antState{id: t.id, from: from, to: to}
+		switch {
+		case from == start && to == start:
+			count.waiting++
+		case from == end:
+			count.arrived++
+		default:
+			count.inTransit++
+		}
+		if from == to {
+			count.occupancy[from]++
+		}
+	}
+	return states, count, progress
+}
+
+// antPosition returns the world position of an ant at the current playback position.
+func (g *Visualization) antPosition(id string) (float64, float64) {
+	states, _, progress := g.antStates()
+	for _, state := range states {
+		if state.id == id {
+			from, to := g.Camera.Positions[state.from], g.Camera.Positions[state.to]
+			return from.X + progress*(to.X-from.X), from.Y + progress*(to.Y-from.Y)
+		}
+	}
+	start := g.Camera.Positions[g.Rooms[0]]
+	return start.X, start.Y
+}
+
+// drawAnts draws the moving ants along their tunnels, one ant in every occupied room with a badge counting the
+// ants standing there, and the counters of waiting, in-transit and arrived ants.
+func (g *Visualization) drawAnts(screen *ebiten.Image) {
+	states, count, progress := g.antStates()
+	width, height := screen.Bounds().Dx(), screen.Bounds().Dy()
+	for room, ants := range count.occupancy {
+		var alpha float32 = 1
+		if !g.onFloor(room) {
+			alpha = 0.25
+		}
+		AntMovement(screen, g.Camera, room, room, 0, antSprite, g.Scale, alpha)
+		x, y := g.Camera.RoomToScreen(room, width, height)
+		label := fmt.Sprint(ants)
+		bx, by := x+6*g.Scale, y-22*g.Scale
+		ebitenutil.DrawRect(screen, bx, by, textWidth(label, g.Scale)+6*g.Scale, lineHeight*g.Scale, fade(color.RGBA{200, 60, 30, 230}, g.onFloor(room)))
+		drawText(screen, label, bx+3*g.Scale, by, g.Scale)
+	}
+	for _, state := range states {
+		if !state.moving() {
+			continue
+		}
+		var alpha float32 = 1
+		if !g.onFloor(state.from) && !g.onFloor(state.to) {
+			alpha = 0.25
+		}
+		AntMovement(screen, g.Camera, state.from, state.to, progress, antSprite, g.Scale, alpha)
+	}
+
+	counters := fmt.Sprintf("Waiting: %d  In transit: %d  Arrived: %d", count.waiting, count.inTransit, count.arrived)
+	hudMargin := 10 * g.Scale
+	drawText(screen, counters, float64(width)-textWidth(counters, g.Scale)-hudMargin, hudMargin+lineHeight*g.Scale, g.Scale)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"lem-in/layout"
+	"lem-in/modules"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Zoom limits, relative to the scale that fits the whole colony on screen
+const (
+	minZoom = 0.05
+	maxZoom = 50
+)
+
+// Camera maps the room positions of the current layout (world) to the screen, without modifying the rooms.
+type Camera struct {
+	Positions layout.Positions // Position of every room in the world
+	X, Y      float64          // World point displayed at the center of the screen
+	Scale     float64          // Screen pixels per world unit
+	FitScale  float64          // Scale that fits the whole colony on screen
+	Follow    string           // Id of the followed ant, empty when the camera is free
+	dragging  bool
+	lastX     int
+	lastY     int
+}
+
+// Fit centers the colony on a screen of the given size, leaving a margin on every side.
+// A colony whose rooms share the same X (or Y) coordinate is only scaled along the other axis.
+func (c *Camera) Fit(rooms []*modules.Room, width, height, margin int) {
+	minX, minY, maxX, maxY := c.bounds(rooms)
+	c.X, c.Y = (minX+maxX)/2, (minY+maxY)/2
+	scaleX := float64(width-2*margin) / (maxX - minX)
+	scaleY := float64(height-2*margin) / (maxY - minY)
+	c.Scale = math.Min(scaleX, scaleY)
+	if math.IsInf(c.Scale, 0) || math.IsNaN(c.Scale) || c.Scale <= 0 {
+		// Every room at the same place, or a screen smaller than the margins
+		c.Scale = 1
+	}
+	c.FitScale = c.Scale
+	c.Follow = ""
+}
+
+// bounds returns the smallest rectangle containing every room.
+func (c *Camera) bounds(rooms []*modules.Room) (minX, minY, maxX, maxY float64) {
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, r := range rooms {
+		p := c.Positions[r]
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	return minX, minY, maxX, maxY
+}
+
+// ToScreen converts a world position to a screen position.
+func (c *Camera) ToScreen(x, y float64, width, height int) (float64, float64) {
+	return (x-c.X)*c.Scale + float64(width)/2, (y-c.Y)*c.Scale + float64(height)/2
+}
+
+// RoomToScreen returns the screen position of a room.
+func (c *Camera) RoomToScreen(room *modules.Room, width, height int) (float64, float64) {
+	p := c.Positions[room]
+	return c.ToScreen(p.X, p.Y, width, height)
+}
+
+// ToWorld converts a screen position to a world position.
+func (c *Camera) ToWorld(x, y float64, width, height int) (float64, float64) {
+	return (x-float64(width)/2)/c.Scale + c.X, (y-float64(height)/2)/c.Scale + c.Y
+}
+
+// Update zooms with the mouse wheel around the cursor and pans when the colony is dragged.
+// mouseUsed tells that the mouse is already used by the playback controls.
+func (c *Camera) Update(width, height int, mouseUsed bool) {
+	cx, cy := ebiten.CursorPosition()
+	if _, wheel := ebiten.Wheel(); wheel != 0 {
+		// The world point under the cursor stays under the cursor
+		wx, wy := c.ToWorld(float64(cx), float64(cy), width, height)
+		scale := c.Scale * math.Pow(1.2, wheel)
+		c.Scale = math.Max(c.FitScale*minZoom, math.Min(scale, c.FitScale*maxZoom))
+		if c.Follow == "" {
+			c.X = wx - (float64(cx)-float64(width)/2)/c.Scale
+			c.Y = wy - (float64(cy)-float64(height)/2)/c.Scale
+		}
+	}
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && !mouseUsed {
+		c.dragging = true
+		c.lastX, c.lastY = cx, cy
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) || mouseUsed {
+		c.dragging = false
+	}
+	if c.dragging && (cx != c.lastX || cy != c.lastY) {
+		c.X -= float64(cx-c.lastX) / c.Scale
+		c.Y -= float64(cy-c.lastY) / c.Scale
+		c.lastX, c.lastY = cx, cy
+		c.Follow = ""
+	}
+}
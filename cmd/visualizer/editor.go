@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"lem-in/colony"
+	"lem-in/layout"
+	"lem-in/modules"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Distance in screen pixels under which a press and a release are a click rather than a drag
+const clickDistance = 4
+
+// Editor is the edit mode of the visualizer: rooms and tunnels are edited with the mouse and the colony is solved
+// again after every change.
+type Editor struct {
+	Active   bool
+	File     string // File written by Ctrl+S
+	selected *modules.Room
+	linking  *modules.Room // First room of a tunnel being added or removed with the right button
+	moving   *modules.Room // Room dragged with the left button
+	pressX   int           // Cursor position when the left button was pressed on empty space
+	pressY   int
+	pressed  bool
+	message  string // Result of the last action, shown in the HUD
+}
+
+// updateEditor toggles the edit mode with E and handles the edits. It returns true when the mouse is used by the
+// editor, so that the camera does not pan at the same time.
+func (g *Visualization) updateEditor(mouseUsed bool) bool {
+	e := g.Editor
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		e.Active = !e.Active
+		e.selected, e.linking, e.moving, e.pressed = nil, nil, nil, false
+		e.message = ""
+		if e.Active {
+			g.useCoordinates()
+		}
+	}
+	if !e.Active {
+		return mouseUsed
+	}
+
+	g.updateEditKeys()
+	if mouseUsed {
+		return true
+	}
+	cx, cy := ebiten.CursorPosition()
+	room := g.roomAt(cx, cy)
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.toggleLink(room)
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		e.selected = room
+		if room != nil {
+			e.moving = room
+		} else {
+			e.pressed, e.pressX, e.pressY = true, cx, cy
+		}
+	}
+	if e.moving != nil {
+		if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			e.moving = nil
+			return false
+		}
+		x, y := g.Camera.ToWorld(float64(cx), float64(cy), g.Width, g.Height)
+		e.moving.Coordinates.X, e.moving.Coordinates.Y = int(math.Round(x)), int(math.Round(y))
+		g.Camera.Positions = layout.FromCoordinates(g.Rooms)
+		return true
+	}
+	if e.pressed && !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		e.pressed = false
+		if math.Hypot(float64(cx-e.pressX), float64(cy-e.pressY)) < clickDistance {
+			g.addRoom(e.pressX, e.pressY)
+		}
+	}
+	return false
+}
+
+// updateEditKeys handles the keys of the edit mode: Delete removes the selected room, S and X make it the start and
+// the end and Ctrl+S saves the colony.
+func (g *Visualization) updateEditKeys() {
+	e := g.Editor
+	if inpututil.IsKeyJustPressed(ebiten.KeyDelete) && e.selected != nil {
+		g.deleteRoom(e.selected)
+	}
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		if ctrl {
+			g.save()
+		} else if e.selected != nil {
+			g.setExtremity(e.selected, 0)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) && e.selected != nil {
+		g.setExtremity(e.selected, len(g.Rooms)-1)
+	}
+}
+
+// useCoordinates switches to the map coordinates, which are the ones edited. When another layout places the rooms
+// elsewhere, its positions become the coordinates of the rooms, 4 units per tunnel.
+func (g *Visualization) useCoordinates() {
+	coordinates := layout.FromCoordinates(g.Rooms)
+	if !maps.Equal(g.Camera.Positions, coordinates) {
+		grid := layout.Grid(g.Camera.Positions, 4, 4)
+		for _, room := range g.Rooms {
+			room.Coordinates = grid[room]
+		}
+	}
+	g.SetPlacement(layout.CoordinatesLayout)
+}
+
+// roomAt returns the room of the displayed floor drawn under a screen position, nil if there is none.
+func (g *Visualization) roomAt(x, y int) *modules.Room {
+	for i := len(g.Rooms) - 1; i >= 0; i-- {
+		room := g.Rooms[i]
+		rx, ry := g.Camera.RoomToScreen(room, g.Width, g.Height)
+		if g.onFloor(room) && math.Abs(float64(x)-rx) <= 10*g.Scale && math.Abs(float64(y)-ry) <= 10*g.Scale {
+			return room
+		}
+	}
+	return nil
+}
+
+// toggleLink starts a tunnel at a room, or adds (removes) the tunnel between the first room and this one when they
+// are not (are) linked yet. A right click on empty space cancels the tunnel.
+func (g *Visualization) toggleLink(room *modules.Room) {
+	e := g.Editor
+	first := e.linking
+	e.linking = nil
+	switch {
+	case room == nil || room == first:
+		return
+	case first == nil:
+		e.linking = room
+		return
+	case slices.Contains(first.Neighbours, room):
+		first.Neighbours = slices.DeleteFunc(first.Neighbours, func(r *modules.Room) bool { return r == room })
+		room.Neighbours = slices.DeleteFunc(room.Neighbours, func(r *modules.Room) bool { return r == first })
+		e.message = "Removed tunnel " + first.Name + "-" + room.Name
+	default:
+		first.Neighbours = append(first.Neighbours, room)
+		room.Neighbours = append(room.Neighbours, first)
+		e.message = "Added tunnel " + first.Name + "-" + room.Name
+	}
+	g.edited()
+}
+
+// addRoom adds a room named R1, R2... at a screen position, on the displayed floor (floor 0 when every floor is
+// displayed). The new room is inserted before the end, which stays the last room.
+func (g *Visualization) addRoom(x, y int) {
+	name := ""
+	for i := 1; name == "" || colony.GetRoomByName(name, g.Rooms) != nil; i++ {
+		name = "R" + strconv.Itoa(i)
+	}
+	wx, wy := g.Camera.ToWorld(float64(x), float64(y), g.Width, g.Height)
+	room := &modules.Room{Name: name, Coordinates: modules.Point{X: int(math.Round(wx)), Y: int(math.Round(wy))}}
+	if g.Floor >= 0 {
+		room.Coordinates.Z = g.Floors[g.Floor]
+	}
+	g.Rooms = slices.Insert(g.Rooms, len(g.Rooms)-1, room)
+	g.Editor.selected = room
+	g.Editor.message = "Added room " + name
+	g.edited()
+}
+
+// deleteRoom removes a room other than the start and the end, with its tunnels.
+func (g *Visualization) deleteRoom(room *modules.Room) {
+	e := g.Editor
+	if room == g.Rooms[0] || room == g.Rooms[len(g.Rooms)-1] {
+		e.message = "The start and the end cannot be deleted"
+		return
+	}
+	for _, neighbour := range room.Neighbours {
+		neighbour.Neighbours = slices.DeleteFunc(neighbour.Neighbours, func(r *modules.Room) bool { return r == room })
+	}
+	g.Rooms = slices.DeleteFunc(g.Rooms, func(r *modules.Room) bool { return r == room })
+	e.selected, e.linking = nil, nil
+	e.message = "Deleted room " + room.Name
+	g.edited()
+}
+
+// setExtremity makes a room the start (index 0) or the end (last index) of the colony. The former start or end takes
+// the place of the room, so that the start and the end are swapped when the end becomes the start.
+func (g *Visualization) setExtremity(room *modules.Room, index int) {
+	i := slices.Index(g.Rooms, room)
+	if i == index {
+		return
+	}
+	g.Rooms[i], g.Rooms[index] = g.Rooms[index], room
+	if index == 0 {
+		g.Editor.message = room.Name + " is the start"
+	} else {
+		g.Editor.message = room.Name + " is the end"
+	}
+	g.edited()
+}
+
+// edited updates the room positions and the floors after a change of the colony, and solves it again. The former
+// moves are cleared right away: their tracks may go through a deleted room or start from the former start.
+func (g *Visualization) edited() {
+	g.Camera.Positions = layout.FromCoordinates(g.Rooms)
+	g.Floors = colony.Floors(g.Rooms)
+	g.Floor = min(g.Floor, len(g.Floors)-1)
+	g.SetMoves(nil)
+	g.resolve()
+}
+
+// save writes the colony to the editor file in the lem-in format.
+func (g *Visualization) save() {
+	file, err := os.Create(g.Editor.File)
+	if err == nil {
+		err = colony.WriteColony(file, g.Solving.Ants, g.Rooms)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		g.Editor.message = fmt.Sprint("Cannot save: ", err)
+		return
+	}
+	g.Editor.message = "Saved to " + g.Editor.File
+}
+
+// drawEditor outlines the selected room, draws the tunnel being added up to the cursor and writes the state and the
+// controls of the edit mode from the given position. It returns the position below its texts.
+func (g *Visualization) drawEditor(screen *ebiten.Image, x, y float64) float64 {
+	e := g.Editor
+	if !e.Active {
+		drawText(screen, "E: edit the colony", x, y, g.Scale)
+		return y + lineHeight*g.Scale
+	}
+	width, height := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if e.selected != nil {
+		rx, ry := g.Camera.RoomToScreen(e.selected, width, height)
+		size := float32(24 * g.Scale)
+		vector.StrokeRect(screen, float32(rx)-size/2, float32(ry)-size/2, size, size, float32(2*g.Scale), color.RGBA{255, 225, 25, 255}, true)
+	}
+	if e.linking != nil {
+		rx, ry := g.Camera.RoomToScreen(e.linking, width, height)
+		cx, cy := ebiten.CursorPosition()
+		vector.StrokeLine(screen, float32(rx), float32(ry), float32(cx), float32(cy), float32(2*g.Scale), color.RGBA{255, 225, 25, 255}, true)
+	}
+
+	lines := []string{
+		"Edit mode (E: leave)",
+		"Click: add/select room, drag: move, right-click two rooms: add/remove tunnel",
+		"Del: delete room, S: set start, X: set end, Ctrl+S: save to " + e.File,
+	}
+	if e.message != "" {
+		lines = append(lines, e.message)
+	}
+	for _, line := range lines {
+		ebitenutil.DrawRect(screen, x, y, textWidth(line, g.Scale), lineHeight*g.Scale, color.RGBA{40, 30, 20, 200})
+		drawText(screen, line, x, y, g.Scale)
+		y += lineHeight * g.Scale
+	}
+	return y
+}
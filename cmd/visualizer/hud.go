@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// Size of a character of the debug font used for every text
+const (
+	charWidth  = 6
+	lineHeight = 16
+)
+
+// textCache keeps the images of the texts drawn with a scale, to render each of them only once.
+var textCache = make(map[string]*ebiten.Image)
+
+// Above this number of cached texts, the cache is emptied (turn counters produce a new text at each turn)
+const maxCachedTexts = 1024
+
+// drawText draws a text with the debug font at the given screen position, enlarged by scale on high-DPI screens.
+func drawText(screen *ebiten.Image, text string, x, y, scale float64) {
+	if scale == 1 {
+		ebitenutil.DebugPrintAt(screen, text, int(x), int(y))
+		return
+	}
+	img, exists := textCache[text]
+	if !exists {
+		if len(textCache) >= maxCachedTexts {
+			clear(textCache)
+		}
+		img = ebiten.NewImage(max(1, charWidth*len(text)), lineHeight)
+		ebitenutil.DebugPrint(img, text)
+		textCache[text] = img
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x, y)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(img, op)
+}
+
+// textWidth returns the width in screen pixels of a text drawn with drawText.
+func textWidth(text string, scale float64) float64 {
+	return float64(charWidth*len(text)) * scale
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"lem-in/modules"
+	"lem-in/render"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Largest number of paths listed in the legend
+const maxLegendPaths = 12
+
+// buildPaths groups the ants by the sequence of rooms they went through, in the order of their first ant. The paths
+// and their colors are the same as in the exported animations.
+func buildPaths(tracks []*track, start *modules.Room) []*render.Path {
+	moves := make([]render.Track, len(tracks))
+	for i, t := range tracks {
+		moves[i] = t.Track
+	}
+	return render.Paths(moves, start)
+}
+
+// linkKey identifies a tunnel whatever the direction.
+func linkKey(a, b *modules.Room) [2]*modules.Room {
+	if a.Name > b.Name {
+		a, b = b, a
+	}
+	return [2]*modules.Room{a, b}
+}
+
+// usedLinks returns the tunnels used by at least one path.
+func usedLinks(paths []*render.Path) map[[2]*modules.Room]bool {
+	used := make(map[[2]*modules.Room]bool)
+	for _, path := range paths {
+		for i := 1; i < len(path.Rooms); i++ {
+			used[linkKey(path.Rooms[i-1], path.Rooms[i])] = true
+		}
+	}
+	return used
+}
+
+// updatePaths toggles the dimming of the unused tunnels with U.
+func (g *Visualization) updatePaths() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		g.DimUnused = !g.DimUnused
+	}
+}
+
+// drawLinks draws every tunnel, then each path of the solution in its color with a thickness proportional to the
+// number of ants sent through it.
+func (g *Visualization) drawLinks(screen *ebiten.Image) {
+	width, height := screen.Bounds().Dx(), screen.Bounds().Dy()
+	for _, room := range g.Rooms {
+		for _, neighbor := range room.Neighbours {
+			col := fade(color.RGBA{124, 180, 50, 255}, g.onFloor(room) && g.onFloor(neighbor))
+			if g.DimUnused && !g.used[linkKey(room, neighbor)] {
+				col = color.RGBA{40, 60, 15, 90}
+			}
+			x1, y1 := g.Camera.RoomToScreen(room, width, height)
+			x2, y2 := g.Camera.RoomToScreen(neighbor, width, height)
+			ebitenutil.DrawLine(screen, x1, y1, x2, y2, col)
+		}
+	}
+
+	most := 1
+	for _, path := range g.Paths {
+		most = max(most, path.Ants)
+	}
+	for p, path := range g.Paths {
+		thickness := float32(g.Scale * (2 + 6*float64(path.Ants)/float64(most)))
+		for i := 1; i < len(path.Rooms); i++ {
+			x1, y1 := g.Camera.RoomToScreen(path.Rooms[i-1], width, height)
+			x2, y2 := g.Camera.RoomToScreen(path.Rooms[i], width, height)
+			col := fade(render.PathColor(p), g.onFloor(path.Rooms[i-1]) && g.onFloor(path.Rooms[i]))
+			vector.StrokeLine(screen, float32(x1), float32(y1), float32(x2), float32(y2), thickness, col, true)
+		}
+	}
+}
+
+// drawLegend lists the paths with their color, length and number of ants, below the texts of the top-left corner.
+func (g *Visualization) drawLegend(screen *ebiten.Image, x, y float64) {
+	if len(g.Paths) == 0 {
+		return
+	}
+	dim := "U: dim unused tunnels"
+	if g.DimUnused {
+		dim = "U: show every tunnel"
+	}
+	drawText(screen, fmt.Sprintf("Paths (%s)", dim), x, y, g.Scale)
+	for i, path := range g.Paths {
+		y += lineHeight * g.Scale
+		if i == maxLegendPaths {
+			drawText(screen, fmt.Sprintf("... and %d more", len(g.Paths)-maxLegendPaths), x, y, g.Scale)
+			break
+		}
+		ebitenutil.DrawRect(screen, x, y+3*g.Scale, 10*g.Scale, 10*g.Scale, render.PathColor(i))
+		drawText(screen, fmt.Sprintf("%d: length %d, %d ants", i+1, len(path.Rooms)-1, path.Ants), x+14*g.Scale, y, g.Scale)
+	}
+}
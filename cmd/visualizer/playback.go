@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Playback speeds, in turns per second
+const (
+	defaultSpeed = 1.2 // The pace of the original animation (0.02 turn per frame at 60 frames per second)
+	minSpeed     = 0.075
+	maxSpeed     = 38.4
+)
+
+// Size of the playback controls drawn at the bottom of the screen, before scaling
+const (
+	controlHeight  = 20
+	controlMargin  = 10
+	timelineHeight = 8
+)
+
+// Playback is the position in the animation and the way it advances.
+type Playback struct {
+	Position float64 // Turns elapsed: the integer part is the current turn, the fraction is the progress of its moves
+	Playing  bool
+	Speed    float64 // Turns per second
+	Turns    int     // Number of turns of the animation
+	Scale    float64 // Size factor of the controls (the device scale factor of the screen)
+	jump     string  // Turn number being typed, jumped to with Enter
+	dragging bool    // The timeline cursor is being dragged
+}
+
+// NewPlayback returns a playback of the given number of turns, playing from the start.
+func NewPlayback(turns int) *Playback {
+	return &Playback{Playing: true, Speed: defaultSpeed, Turns: turns, Scale: 1}
+}
+
+// Current returns the index of the turn being animated and the progress of its moves, between 0 and 1.
+func (p *Playback) Current() (int, float64) {
+	if p.Turns == 0 {
+		return 0, 0
+	}
+	if p.Position >= float64(p.Turns) {
+		return p.Turns - 1, 1
+	}
+	turn := math.Floor(p.Position)
+	return int(turn), p.Position - turn
+}
+
+// Seek moves to a position, kept between the start and the end of the animation.
+func (p *Playback) Seek(position float64) {
+	p.Position = math.Max(0, math.Min(position, float64(p.Turns)))
+}
+
+// Step moves to the start of the next (delta 1) or previous (delta -1) turn and pauses.
+func (p *Playback) Step(delta int) {
+	p.Playing = false
+	if delta > 0 {
+		p.Seek(math.Floor(p.Position) + 1)
+	} else if p.Position == math.Floor(p.Position) {
+		p.Seek(p.Position - 1)
+	} else {
+		p.Seek(math.Floor(p.Position))
+	}
+}
+
+// SetSpeed multiplies the speed by a factor, within the allowed range.
+func (p *Playback) SetSpeed(factor float64) {
+	p.Speed = math.Max(minSpeed, math.Min(p.Speed*factor, maxSpeed))
+}
+
+// TogglePlay pauses or resumes the animation; resuming at the end restarts from the beginning.
+func (p *Playback) TogglePlay() {
+	if !p.Playing && p.Position >= float64(p.Turns) {
+		p.Position = 0
+	}
+	p.Playing = !p.Playing
+}
+
+// Update advances the animation and handles the playback keys and controls:
+// Space play/pause, Left/Right step, Home/End first/last turn, Up/Down speed, digits then Enter jump to a turn.
+// It returns true when the mouse is used by the controls.
+func (p *Playback) Update(width, height int) bool {
+	if p.Playing {
+		p.Seek(p.Position + p.Speed/float64(ebiten.TPS()))
+		if p.Position >= float64(p.Turns) {
+			p.Playing = false
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		p.TogglePlay()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		p.Step(1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		p.Step(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		p.Seek(0)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
+		p.Seek(float64(p.Turns))
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		p.SetSpeed(2)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		p.SetSpeed(0.5)
+	}
+	p.updateJump()
+	return p.updateMouse(width, height)
+}
+
+// updateJump reads a typed turn number and jumps to the start of that turn when Enter is pressed.
+func (p *Playback) updateJump() {
+	for _, char := range ebiten.AppendInputChars(nil) {
+		if char >= '0' && char <= '9' && len(p.jump) < 9 {
+			p.jump += string(char)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(p.jump) > 0 {
+		p.jump = p.jump[:len(p.jump)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && p.jump != "" {
+		turn, _ := strconv.Atoi(p.jump)
+		p.Playing = false
+		p.Seek(float64(turn - 1))
+		p.jump = ""
+	}
+}
+
+// Typing reports whether a turn number is being typed.
+func (p *Playback) Typing() bool {
+	return p.jump != ""
+}
+
+// CancelJump forgets the turn number being typed. Escape is read by the camera, which calls it before it stops
+// following an ant.
+func (p *Playback) CancelJump() {
+	p.jump = ""
+}
+
+// button is an on-screen control.
+type button struct {
+	label         string
+	x, y          float64
+	width, height float64
+	action        func(p *Playback)
+}
+
+// buttons returns the on-screen controls, laid out above the timeline.
+func (p *Playback) buttons(height int) []button {
+	playLabel := "Play"
+	if p.Playing {
+		playLabel = "Pause"
+	}
+	y := float64(height) - p.px(controlMargin+timelineHeight+controlMargin+controlHeight)
+	controls := []button{
+		{label: "|<", width: 24, action: func(p *Playback) { p.Step(-1) }},
+		{label: playLabel, width: 48, action: func(p *Playback) { p.TogglePlay() }},
+		{label: ">|", width: 24, action: func(p *Playback) { p.Step(1) }},
+		{label: "-", width: 20, action: func(p *Playback) { p.SetSpeed(0.5) }},
+		{label: "+", width: 20, action: func(p *Playback) { p.SetSpeed(2) }},
+	}
+	x := p.px(controlMargin)
+	for i := range controls {
+		controls[i].x, controls[i].y = x, y
+		controls[i].width, controls[i].height = p.px(controls[i].width), p.px(controlHeight)
+		x += controls[i].width + p.px(4)
+	}
+	return controls
+}
+
+// px converts a size of the controls to screen pixels, according to the HUD scale.
+func (p *Playback) px(size float64) float64 {
+	return size * p.Scale
+}
+
+// timeline returns the position and width of the timeline bar.
+func (p *Playback) timeline(width, height int) (x, y, w float64) {
+	return p.px(controlMargin), float64(height) - p.px(controlMargin+timelineHeight), float64(width) - 2*p.px(controlMargin)
+}
+
+// updateMouse handles clicks on the buttons and dragging along the timeline.
+func (p *Playback) updateMouse(width, height int) bool {
+	x, y := ebiten.CursorPosition()
+	cx, cy := float64(x), float64(y)
+	tx, ty, tw := p.timeline(width, height)
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		for _, b := range p.buttons(height) {
+			if cx >= b.x && cx < b.x+b.width && cy >= b.y && cy < b.y+b.height {
+				b.action(p)
+				return true
+			}
+		}
+		if cx >= tx && cx <= tx+tw && cy >= ty-p.px(4) && cy <= ty+p.px(timelineHeight+4) {
+			p.dragging = true
+		}
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		p.dragging = false
+	}
+	if p.dragging {
+		p.Playing = false
+		p.Seek((cx - tx) / tw * float64(p.Turns))
+		return true
+	}
+	return false
+}
+
+// Draw draws the buttons, the speed, the typed turn number and the timeline with its cursor.
+func (p *Playback) Draw(screen *ebiten.Image) {
+	width, height := screen.Bounds().Dx(), screen.Bounds().Dy()
+	var x, y float64
+	for _, b := range p.buttons(height) {
+		ebitenutil.DrawRect(screen, b.x, b.y, b.width, b.height, color.RGBA{40, 30, 20, 200})
+		drawText(screen, b.label, b.x+p.px(4), b.y+p.px(2), p.Scale)
+		x, y = b.x+b.width, b.y
+	}
+	info := fmt.Sprintf("Speed: %.3g turns/s", p.Speed)
+	if p.jump != "" {
+		info += fmt.Sprintf("   Go to turn: %s_ (Enter)", p.jump)
+	}
+	drawText(screen, info, x+p.px(10), y+p.px(2), p.Scale)
+
+	tx, ty, tw := p.timeline(width, height)
+	ebitenutil.DrawRect(screen, tx, ty, tw, p.px(timelineHeight), color.RGBA{40, 30, 20, 200})
+	if p.Turns > 0 {
+		done := tw * p.Position / float64(p.Turns)
+		ebitenutil.DrawRect(screen, tx, ty, done, p.px(timelineHeight), color.RGBA{124, 180, 50, 255})
+		ebitenutil.DrawRect(screen, tx+done-p.px(2), ty-p.px(3), p.px(4), p.px(timelineHeight+6), color.White)
+	}
+}
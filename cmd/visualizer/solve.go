@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lem-in/colony"
+	"lem-in/datas"
+	"lem-in/modules"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Solving holds the parameters of the in-process resolution, used with -map, after every edit and when the colony
+// is solved again from the window.
+type Solving struct {
+	Solver  string             // Name of the solver (see colony.SolverNames)
+	Ants    int64              // Number of ants sent through the colony
+	Timeout time.Duration      // Time given to the solver: the best solution found so far is used when it runs out
+	Solved  bool               // The moves come from the in-process solver, not from stdin
+	status  string             // Result of the last resolution, shown in the HUD
+	cancel  context.CancelFunc // Cancels the resolution in progress
+	started int                // Number of resolutions started, which identifies the latest one
+	results chan solveResult   // Results of the resolutions running in the background
+}
+
+// solveResult is the outcome of a resolution started by resolve.
+type solveResult struct {
+	id       int  // Value of Solving.started when the resolution was started
+	exact    bool // The solver compares every move schedule (colony.Exact), not only those along independent paths
+	solution modules.Solution
+	err      error
+}
+
+// updateSolving handles the resolution keys: R solves the colony again, V switches to the next solver and + / -
+// change the number of ants (by 10 with Shift), which solves the colony again.
+func (g *Visualization) updateSolving() {
+	s := g.Solving
+	g.applySolution()
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.resolve()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		names := colony.SolverNames()
+		s.Solver = names[(slices.Index(names, s.Solver)+1)%len(names)]
+		g.resolve()
+	}
+	var step int64 = 1
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		step = 10
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyKPAdd) {
+		s.Ants += step
+		g.resolve()
+	}
+	if (inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyKPSubtract)) && s.Ants > 1 {
+		s.Ants = max(1, s.Ants-step)
+		g.resolve()
+	}
+}
+
+// resolve starts solving the colony in the background, so that the window keeps responding. The resolution in
+// progress, if any, is cancelled: only the result of the latest one is used, by applySolution. The solver works on a
+// copy of the rooms, which the editor may change in the meantime.
+func (g *Visualization) resolve() {
+	s := g.Solving
+	s.Solved = true
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.started++
+	solver, err := colony.NewSolver(s.Solver, colony.Options{})
+	if err != nil {
+		s.status = err.Error()
+		return
+	}
+	if s.results == nil {
+		s.results = make(chan solveResult)
+	}
+	id, rooms, ants, results := s.started, colony.CloneGraph(g.Rooms), s.Ants, s.results
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	s.cancel = cancel
+	s.status = "solving..."
+	go func() {
+		defer cancel()
+		solution, err := solver.Solve(ctx, rooms, ants)
+		_, exact := solver.(colony.Exact)
+		results <- solveResult{id: id, exact: exact, solution: solution, err: err}
+	}()
+}
+
+// applySolution replaces the animation by the moves of the latest resolution once it is finished. The results of
+// the cancelled resolutions are dropped.
+func (g *Visualization) applySolution() {
+	for {
+		select {
+		case result := <-g.Solving.results:
+			g.apply(result)
+		default:
+			return
+		}
+	}
+}
+
+// apply replaces the animation by the moves of a resolution, if it is the latest one.
+func (g *Visualization) apply(result solveResult) {
+	s := g.Solving
+	if result.id != s.started {
+		return
+	}
+	s.cancel = nil
+	if result.err != nil {
+		g.SetMoves(nil)
+		s.status = result.err.Error()
+		return
+	}
+	var moves strings.Builder
+	colony.WriteSolution(&moves, result.solution)
+	g.SetMoves(strings.Split(moves.String(), "\n"))
+	switch {
+	case !result.solution.Optimal:
+		s.status = "best-effort, stopped after " + s.Timeout.String()
+	case result.exact:
+		s.status = "proven optimal"
+	default:
+		s.status = "optimal among independent paths"
+	}
+}
+
+// drawSolving writes the solver, the number of ants and the result of the last resolution.
+func (g *Visualization) drawSolving(screen *ebiten.Image, x, y float64) {
+	s := g.Solving
+	text := "Moves read from stdin (R: solve here, V: solver, +/-: ants)"
+	if s.Solved {
+		text = fmt.Sprintf("Solver: %s, %d ants, %d turns, %s (R: solve again, V: solver, +/-: ants)",
+			s.Solver, s.Ants, len(g.Turns), s.status)
+	}
+	drawText(screen, text, x, y, g.Scale)
+}
+
+// readMap reads the instructions of a map file. A file that does not exist is looked for in files/, like lem-in does.
+func readMap(path string) ([]string, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		path = "files/" + path
+	}
+	return datas.ReadFile(path)
+}
@@ -2,18 +2,34 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"image/color"
+	"io"
 	"lem-in/colony"
 	"lem-in/datas"
+	"lem-in/layout"
 	"lem-in/modules"
+	"lem-in/render"
 	"log"
 	"math"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Initial size of the visualizer window
+const (
+	screenWidth  = 800
+	screenHeight = 600
+	margin       = 50 // Space left around the colony when it is fitted to the screen
 )
 
 var antSprite *ebiten.Image
@@ -35,32 +51,148 @@ func init() {
 
 // Visualization holds the state for the graphical simulation.
 type Visualization struct {
-	Rooms       []*modules.Room
-	Ants        []*modules.Ant
-	Turns       [][]*modules.Ant
-	CurrentTurn int
+	Rooms     []*modules.Room
+	Ants      []*modules.Ant
+	Turns     [][]*modules.Ant
+	Tracks    []*track       // Moves of every ant, in the order of Ants
+	Paths     []*render.Path // Paths followed by the ants
+	DimUnused bool           // Tunnels used by no path are dimmed
+	used      map[[2]*modules.Room]bool
+	Playback  *Playback
+	Camera    *Camera
+	Editor    *Editor
+	Solving   *Solving
+	Placement string  // Name of the room layout (see layout.Names)
+	Floors    []int   // Sorted floors (Z coordinates) of the colony
+	Floor     int     // Index of the displayed floor in Floors, -1 to display every floor
+	Width     int     // Width of the screen in device pixels, updated by Layout
+	Height    int     // Height of the screen in device pixels, updated by Layout
+	Scale     float64 // Device scale factor, applied to the HUD, the rooms and the ants
 }
 
-func (g *Visualization) Update() error {
-	if g.CurrentTurn >= len(g.Turns) {
-		return nil
+// updateFloor switches the displayed floor with PageUp/PageDown, F toggles the display of every floor.
+func (g *Visualization) updateFloor() {
+	if len(g.Floors) < 2 {
+		return
 	}
-
-	allDone := true
-	for _, ant := range g.Turns[g.CurrentTurn] {
-		if ant.T < 1.0 {
-			ant.T += 0.02
-			allDone = false
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		if g.Floor < 0 {
+			g.Floor = 0
+		} else {
+			g.Floor = -1
 		}
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageUp) && g.Floor < len(g.Floors)-1 {
+		g.Floor++
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPageDown) && g.Floor > 0 {
+		g.Floor--
+	}
+}
+
+// onFloor reports whether a room belongs to the displayed floor.
+func (g *Visualization) onFloor(room *modules.Room) bool {
+	return g.Floor < 0 || room.Coordinates.Z == g.Floors[g.Floor]
+}
+
+// fade returns a translucent version of a color for elements outside the displayed floor.
+func fade(col color.Color, visible bool) color.Color {
+	if visible {
+		return col
+	}
+	r, g, b, a := col.RGBA()
+	return color.RGBA{uint8(r >> 10), uint8(g >> 10), uint8(b >> 10), uint8(a >> 10)}
+}
+
+// SetPlacement places the rooms with a layout of layout.Names and fits the colony to the screen.
+func (g *Visualization) SetPlacement(name string) error {
+	positions, err := layout.Compute(name, g.Rooms)
+	if err != nil {
+		return err
+	}
+	g.Placement = name
+	g.Camera.Positions = positions
+	follow := g.Camera.Follow
+	g.Camera.Fit(g.Rooms, g.Width, g.Height, int(margin*g.Scale))
+	g.Camera.Follow = follow
+	return nil
+}
 
-	if allDone {
-		g.CurrentTurn++
+// updatePlacement switches to the next layout with L, except in the edit mode which works on the map coordinates.
+func (g *Visualization) updatePlacement() error {
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) && !g.Editor.Active {
+		names := layout.Names()
+		return g.SetPlacement(names[(slices.Index(names, g.Placement)+1)%len(names)])
 	}
+	return nil
+}
 
+func (g *Visualization) Update() error {
+	if err := g.updatePlacement(); err != nil {
+		return err
+	}
+	g.updateFloor()
+	g.updatePaths()
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	}
+	mouseUsed := g.Playback.Update(g.Width, g.Height)
+	g.updateSolving()
+	mouseUsed = g.updateEditor(mouseUsed)
+	g.updateCamera(mouseUsed)
 	return nil
 }
 
+// updateCamera handles the camera: C fits the colony to the screen, Tab / Shift+Tab follow the next / previous ant,
+// Escape cancels the turn number being typed, or else stops following. The mouse wheel zooms and dragging the
+// colony pans.
+func (g *Visualization) updateCamera(mouseUsed bool) {
+	g.Camera.Update(g.Width, g.Height, mouseUsed)
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		g.Camera.Fit(g.Rooms, g.Width, g.Height, int(margin*g.Scale))
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if g.Playback.Typing() {
+			g.Playback.CancelJump()
+		} else {
+			g.Camera.Follow = ""
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) && len(g.Ants) > 0 {
+		// Without any ant followed, Tab starts from the first ant and Shift+Tab from the last one
+		shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+		next := 0
+		if shift {
+			next = len(g.Ants) - 1
+		}
+		for i, ant := range g.Ants {
+			if ant.Id == g.Camera.Follow {
+				next = i + 1
+				if shift {
+					next = i - 1 + len(g.Ants)
+				}
+			}
+		}
+		g.Camera.Follow = g.Ants[next%len(g.Ants)].Id
+	}
+	if g.Camera.Follow != "" {
+		g.Camera.X, g.Camera.Y = g.antPosition(g.Camera.Follow)
+	}
+}
+
+// SetMoves replaces the animation by the given movement lines, from the first turn.
+func (g *Visualization) SetMoves(movements []string) {
+	g.Turns, g.Ants = ApplyMovements(movements, g.Rooms)
+	g.Tracks = buildTracks(g.Turns, g.Ants)
+	g.Paths = buildPaths(g.Tracks, g.Rooms[0])
+	g.used = usedLinks(g.Paths)
+	playback := NewPlayback(len(g.Turns))
+	if g.Playback != nil {
+		playback.Scale = g.Playback.Scale
+	}
+	g.Playback = playback
+}
+
 // ApplyMovements parses the movement lines and returns the turns and all ants.
 func ApplyMovements(lines []string, rooms []*modules.Room) ([][]*modules.Ant, []*modules.Ant) {
 	var turns [][]*modules.Ant
@@ -115,29 +247,36 @@ func ApplyMovements(lines []string, rooms []*modules.Room) ([][]*modules.Ant, []
 		turns = append(turns, turn)
 	}
 
-	// Extract the global slice of ants
+	// Extract the global slice of ants, sorted by number
 	var ants []*modules.Ant
 	for _, ant := range antMap {
 		ants = append(ants, ant)
 	}
+	sort.Slice(ants, func(i, j int) bool {
+		a, _ := strconv.Atoi(ants[i].Id)
+		b, _ := strconv.Atoi(ants[j].Id)
+		return a < b
+	})
 
 	return turns, ants
 }
 
 // AntMovement draws an ant moving from one room to another, with smooth oscillation and rotation.
-func AntMovement(screen *ebiten.Image, from, to *modules.Room, progress float64, sprite *ebiten.Image) {
+// The sprite is enlarged by scale on high-DPI screens.
+func AntMovement(screen *ebiten.Image, camera *Camera, from, to *modules.Room, progress float64, sprite *ebiten.Image, scale float64, alpha float32) {
 	if from == nil || to == nil || sprite == nil {
 		return
 	}
+	width, height := screen.Bounds().Dx(), screen.Bounds().Dy()
+	fromX, fromY := camera.RoomToScreen(from, width, height)
+	toX, toY := camera.RoomToScreen(to, width, height)
 
 	// Interpolated position
-	x := (1-progress)*float64(from.Coordinates.X) + progress*float64(to.Coordinates.X)
-	y := (1-progress)*float64(from.Coordinates.Y) + progress*float64(to.Coordinates.Y)
+	x := (1-progress)*fromX + progress*toX
+	y := (1-progress)*fromY + progress*toY
 
 	// Direction angle
-	dx := float64(to.Coordinates.X - from.Coordinates.X)
-	dy := float64(to.Coordinates.Y - from.Coordinates.Y)
-	angle := math.Atan2(dy, dx)
+	angle := math.Atan2(toY-fromY, toX-fromX)
 
 	// Smooth oscillation
 	oscillation := math.Sin(progress*10*math.Pi) * (5 * math.Pi / 180) // ±5°
@@ -148,8 +287,10 @@ func AntMovement(screen *ebiten.Image, from, to *modules.Room, progress float64,
 	// Draw with rotation
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(-float64(sprite.Bounds().Dx())/2, -float64(sprite.Bounds().Dy())/2)
+	op.GeoM.Scale(scale, scale)
 	op.GeoM.Rotate(totalRotation)
 	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleAlpha(alpha)
 	screen.DrawImage(sprite, op)
 }
 
@@ -163,19 +304,8 @@ func (g *Visualization) Draw(screen *ebiten.Image) {
 	)
 	screen.DrawImage(dirt, op)
 
-	// Draw links
-	for _, room := range g.Rooms {
-		for _, neighbor := range room.Neighbours {
-			ebitenutil.DrawLine(
-				screen,
-				float64(room.Coordinates.X),
-				float64(room.Coordinates.Y),
-				float64(neighbor.Coordinates.X),
-				float64(neighbor.Coordinates.Y),
-				color.RGBA{124, 180, 50, 255},
-			)
-		}
-	}
+	g.drawLinks(screen)
+	width, height := screen.Bounds().Dx(), screen.Bounds().Dy()
 
 	// Draw rooms
 	for i, room := range g.Rooms {
@@ -185,75 +315,65 @@ func (g *Visualization) Draw(screen *ebiten.Image) {
 		} else if i == len(g.Rooms)-1 {
 			col = color.RGBA{255, 0, 0, 255} // Red
 		}
-		ebitenutil.DrawRect(screen, float64(room.Coordinates.X)-10, float64(room.Coordinates.Y)-10, 20, 20, col)
-		ebitenutil.DebugPrintAt(screen, room.Name, room.Coordinates.X+12, room.Coordinates.Y-10)
+		x, y := g.Camera.RoomToScreen(room, width, height)
+		ebitenutil.DrawRect(screen, x-10*g.Scale, y-10*g.Scale, 20*g.Scale, 20*g.Scale, fade(col, g.onFloor(room)))
+		if g.onFloor(room) {
+			drawText(screen, room.Name, x+12*g.Scale, y-10*g.Scale, g.Scale)
+		}
 	}
 
-	if g.CurrentTurn < len(g.Turns) {
-		for _, ant := range g.Turns[g.CurrentTurn] {
-			AntMovement(screen, ant.LastRoom, ant.CurrentRoom, ant.T, antSprite)
+	g.drawAnts(screen)
+	g.Playback.Draw(screen)
+	turn, _ := g.Playback.Current()
+	// Without any turn (no solution yet), the counter shows 0 / 0
+	turnText := fmt.Sprintf("Turn: %d / %d", min(turn+1, len(g.Turns)), len(g.Turns))
+	// The HUD is placed relative to the edges of the screen
+	hudMargin := 10 * g.Scale
+	drawText(screen, turnText, float64(width)-textWidth(turnText, g.Scale)-hudMargin, hudMargin, g.Scale)
+	if len(g.Floors) > 1 {
+		floorText := "Floor: all"
+		if g.Floor >= 0 {
+			floorText = fmt.Sprintf("Floor: %d", g.Floors[g.Floor])
 		}
+		drawText(screen, floorText+" (PgUp/PgDn, F: all)", hudMargin, hudMargin, g.Scale)
 	}
-
-	turnText := fmt.Sprintf("Turn: %d / %d", g.CurrentTurn+1, len(g.Turns)+1)
-	ebitenutil.DebugPrintAt(screen, turnText, 700, 10)
+	if g.Camera.Follow != "" {
+		drawText(screen, "Following ant "+g.Camera.Follow+" (Tab: next, Esc: stop)", hudMargin, hudMargin+lineHeight*g.Scale, g.Scale)
+	}
+	drawText(screen, "Layout: "+g.Placement+" (L)", hudMargin, hudMargin+2*lineHeight*g.Scale, g.Scale)
+	g.drawSolving(screen, hudMargin, hudMargin+3*lineHeight*g.Scale)
+	y := g.drawEditor(screen, hudMargin, hudMargin+4*lineHeight*g.Scale)
+	g.drawLegend(screen, hudMargin, y+lineHeight*g.Scale)
 }
 
+// Layout renders at the real resolution of the window (device pixels), and fits the colony again when the window
+// is resized or goes fullscreen.
 func (g *Visualization) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return 800, 600
-}
-
-// centerColony recenters and scales the colony to fit within the screen, applying a margin.
-func centerColony(rooms []*modules.Room, screenWidth, screenHeight int, margin int) {
-	minX, minY := 99999, 99999
-	maxX, maxY := 0, 0
-
-	for _, r := range rooms {
-		if r.Coordinates.X < minX {
-			minX = r.Coordinates.X
-		}
-		if r.Coordinates.Y < minY {
-			minY = r.Coordinates.Y
-		}
-		if r.Coordinates.X > maxX {
-			maxX = r.Coordinates.X
-		}
-		if r.Coordinates.Y > maxY {
-			maxY = r.Coordinates.Y
-		}
-	}
-
-	colonyWidth := maxX - minX
-	colonyHeight := maxY - minY
-
-	// Apply margin by reducing available space
-	availableWidth := screenWidth - 2*margin
-	availableHeight := screenHeight - 2*margin
-
-	// Calculate scale factor to fit colony in window
-	scaleX := float64(availableWidth) / float64(colonyWidth)
-	scaleY := float64(availableHeight) / float64(colonyHeight)
-	scale := math.Min(scaleX, scaleY)
-
-	// Apply centering and margin
-	for _, r := range rooms {
-		r.Coordinates.X = int((float64(r.Coordinates.X-minX) * scale)) + margin
-		r.Coordinates.Y = int((float64(r.Coordinates.Y-minY) * scale)) + margin
+	scale := ebiten.Monitor().DeviceScaleFactor()
+	width, height := int(float64(outsideWidth)*scale), int(float64(outsideHeight)*scale)
+	if width != g.Width || height != g.Height || scale != g.Scale {
+		g.Width, g.Height, g.Scale = width, height, scale
+		g.Playback.Scale = scale
+		follow := g.Camera.Follow
+		g.Camera.Fit(g.Rooms, width, height, int(margin*scale))
+		g.Camera.Follow = follow
 	}
+	return width, height
 }
 
-// main reads input, parses instructions and movements, and runs the visualization.
-func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	var instructions []string
-	var movements []string
+// readInput reads the output of a solver: the instructions of the map, then the moves. The moves end with the first
+// line that is not a move (the summary printed by lem-in).
+func readInput(input io.Reader) (instructions, movements []string, err error) {
+	scanner := bufio.NewScanner(input)
 	instructionsDone := false
-
 	for scanner.Scan() {
 		line := scanner.Text()
 		if !instructionsDone && strings.HasPrefix(line, "L") {
 			instructionsDone = true
 		}
+		if instructionsDone && !strings.HasPrefix(line, "L") {
+			break
+		}
 
 		if instructionsDone {
 			movements = append(movements, line)
@@ -261,9 +381,35 @@ func main() {
 			instructions = append(instructions, line)
 		}
 	}
+	return instructions, movements, scanner.Err()
+}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
+// main reads a map and its moves from stdin (pipe mode) or solves a map file in-process (-map), then runs the
+// visualization.
+func main() {
+	layoutName := flag.String("layout", layout.AutoLayout, "room layout: "+strings.Join(layout.Names(), ", ")+
+		" (auto uses the map coordinates unless rooms overlap)")
+	saveFile := flag.String("save", "files/edited.txt", "file written by Ctrl+S in the edit mode")
+	mapFile := flag.String("map", "", "map to solve in-process instead of reading a solver output from stdin (a path, or a file of files/)")
+	ants := flag.Int64("ants", 0, "number of ants sent through the colony when it is solved in-process (0 keeps the number of the map)")
+	solverName := flag.String("solver", "bruteforce", "in-process solver ("+strings.Join(colony.SolverNames(), ", ")+")")
+	timeout := flag.Duration("timeout", 2*time.Second, "time given to the in-process solver, which keeps the best solution found")
+	flag.Parse()
+	if _, err := colony.NewSolver(*solverName, colony.Options{}); err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
+	}
+
+	var instructions, movements []string
+	var err error
+	if *mapFile != "" {
+		instructions, err = readMap(*mapFile)
+	} else {
+		instructions, movements, err = readInput(os.Stdin)
+	}
+	if err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
 	}
 	filedatas := datas.SaveDatas(instructions)
 	datas.CheckErrors(&filedatas)
@@ -275,16 +421,31 @@ func main() {
 	}
 	rooms := colony.CreatRooms(filedatas)
 	colony.CreatColony(filedatas, rooms)
-	centerColony(rooms, 800, 600, 50)
-	turns, ants := ApplyMovements(movements, rooms)
 	visualization := &Visualization{
-		Rooms:       rooms,
-		Turns:       turns,
-		Ants:        ants,
-		CurrentTurn: 0,
+		Rooms:   rooms,
+		Camera:  &Camera{},
+		Editor:  &Editor{File: *saveFile},
+		Solving: &Solving{Solver: *solverName, Ants: filedatas.NbAnts, Timeout: *timeout},
+		Floors:  colony.Floors(rooms),
+		Floor:   -1,
+	}
+	if *ants > 0 {
+		visualization.Solving.Ants = *ants
+	}
+	if *mapFile != "" || *ants > 0 {
+		// The window opens on the colony alone, the moves being shown once the background resolution is done
+		visualization.SetMoves(nil)
+		visualization.resolve()
+	} else {
+		visualization.SetMoves(movements)
+	}
+	if err := visualization.SetPlacement(*layoutName); err != nil {
+		fmt.Println(fmt.Errorf("error : %w", err))
+		return
 	}
 
-	ebiten.SetWindowSize(800, 600)
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetWindowTitle("Lem-in Visualizer")
 	if err := ebiten.RunGame(visualization); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,115 @@
+// Package colony provides the link advisor, which suggests tunnels to dig to speed up a colony.
+package colony
+
+import (
+	"lem-in/modules"
+	"math"
+	"sort"
+)
+
+// Tunnel suggéré entre deux salles existantes.
+type SuggestedLink struct {
+	From, To string
+	Distance float64 // Distance euclidienne entre les deux salles (étage compris)
+}
+
+// Résultat du conseiller : les tunnels à ajouter et le nombre de tours avant et après (0 si la sortie est inaccessible).
+type LinkAdvice struct {
+	Links   []SuggestedLink
+	Before  int64
+	After   int64
+	Reached bool // After <= objectif demandé
+	Skipped int  // Tunnels possibles écartés (les plus longs) au-delà de maxAdviceCandidates
+}
+
+// Nombre maximal de tunnels essayés à chaque étape. Chaque essai met à jour le flot (annulation des cycles de coût
+// négatif) : sans limite, les n² / 2 paires de salles d'une grande colonie demanderaient des minutes par étape.
+const maxAdviceCandidates = 2000
+
+// Propose un ensemble de nouveaux tunnels entre salles existantes pour atteindre target tours au plus avec ants fourmis.
+// Si maxDistance > 0, seuls les tunnels plus courts que maxDistance sont proposés. Au-delà de maxAdviceCandidates
+// tunnels possibles, seuls les plus courts sont essayés (les autres sont comptés dans Skipped).
+// Recherche gloutonne : à chaque étape, on ajoute le tunnel qui diminue le plus le nombre de tours (le plus court à égalité),
+// puis on retire les tunnels devenus inutiles. Un seul tunnel est toujours proposé s'il suffit, au-delà l'ensemble trouvé
+// n'est pas forcément le plus petit possible. Chaque essai réutilise le flot de IncrementalSolver.
+// La colonie reçue n'est pas modifiée.
+func AdviseLinks(graph []*modules.Room, ants, target int64, maxDistance float64, opts Options) LinkAdvice {
+	rooms := CloneGraph(graph)
+	solver := NewIncrementalSolver(rooms, ants, opts)
+	advice := LinkAdvice{Before: turnsOrZero(solver)}
+	turns := solverTurns(solver)
+
+	var candidates []SuggestedLink
+	for i, room := range rooms {
+		for _, other := range rooms[i+1:] {
+			distance := roomDistance(room, other)
+			if !containsRoom(room.Neighbours, other) && (maxDistance <= 0 || distance <= maxDistance) {
+				candidates = append(candidates, SuggestedLink{From: room.Name, To: other.Name, Distance: distance})
+			}
+		}
+	}
+	if len(candidates) > maxAdviceCandidates {
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Distance < candidates[j].Distance })
+		advice.Skipped = len(candidates) - maxAdviceCandidates
+		candidates = candidates[:maxAdviceCandidates]
+	}
+
+	var chosen []SuggestedLink
+	for turns > target && len(candidates) > 0 {
+		best, bestTurns := -1, turns
+		for i, link := range candidates {
+			solver.AddLink(link.From, link.To)
+			if t := solverTurns(solver); t < bestTurns || (t == bestTurns && best >= 0 && link.Distance < candidates[best].Distance) {
+				best, bestTurns = i, t
+			}
+			solver.RemoveLink(link.From, link.To)
+		}
+		// Aucun tunnel ne fait gagner de tour : l'objectif ne peut pas être atteint
+		if best < 0 {
+			break
+		}
+		solver.AddLink(candidates[best].From, candidates[best].To)
+		chosen = append(chosen, candidates[best])
+		candidates = append(candidates[:best], candidates[best+1:]...)
+		turns = bestTurns
+	}
+
+	// Un tunnel choisi tôt peut être rendu inutile par les suivants
+	if turns <= target {
+		for i := 0; i < len(chosen); i++ {
+			solver.RemoveLink(chosen[i].From, chosen[i].To)
+			if solverTurns(solver) <= target {
+				chosen = append(chosen[:i], chosen[i+1:]...)
+				i--
+			} else {
+				solver.AddLink(chosen[i].From, chosen[i].To)
+			}
+		}
+	}
+	advice.Links, advice.After = chosen, turnsOrZero(solver)
+	advice.Reached = solverTurns(solver) <= target
+	return advice
+}
+
+// Nombre de tours de la meilleure solution, math.MaxInt64 si la sortie est inaccessible.
+func solverTurns(solver *IncrementalSolver) int64 {
+	solution, err := solver.Best()
+	if err != nil {
+		return math.MaxInt64
+	}
+	return solution.Turns
+}
+
+func turnsOrZero(solver *IncrementalSolver) int64 {
+	if turns := solverTurns(solver); turns != math.MaxInt64 {
+		return turns
+	}
+	return 0
+}
+
+func roomDistance(a, b *modules.Room) float64 {
+	dx := float64(a.Coordinates.X - b.Coordinates.X)
+	dy := float64(a.Coordinates.Y - b.Coordinates.Y)
+	dz := float64(a.Coordinates.Z - b.Coordinates.Z)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
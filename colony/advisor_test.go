@@ -0,0 +1,40 @@
+package colony
+
+import "testing"
+
+// Un couloir start-a-b-end : seul le tunnel start-end amène la fourmi en 1 tour.
+var corridorMap = []string{
+	"1",
+	"##start",
+	"start 0 0",
+	"a 1 0",
+	"b 2 0",
+	"##end",
+	"end 3 0",
+	"start-a",
+	"a-b",
+	"b-end",
+}
+
+func TestAdviseLinksSingleTunnel(t *testing.T) {
+	graph, ants := parseMap(t, corridorMap)
+	advice := AdviseLinks(graph, ants, 1, 0, Options{})
+	if advice.Before != 3 || advice.After != 1 || !advice.Reached {
+		t.Fatalf("before %d, after %d, reached %v, want 3, 1, true", advice.Before, advice.After, advice.Reached)
+	}
+	if len(advice.Links) != 1 || advice.Links[0].From != "start" || advice.Links[0].To != "end" {
+		t.Errorf("links %v, want start-end", advice.Links)
+	}
+	if advice.Skipped != 0 {
+		t.Errorf("%d tunnels skipped, want 0", advice.Skipped)
+	}
+	if len(graph[0].Neighbours) != 1 {
+		t.Errorf("the colony was modified : start has %d neighbours", len(graph[0].Neighbours))
+	}
+
+	// Limité à 2.5, start-end (distance 3) n'est plus proposé : un tunnel de 2 ne fait gagner qu'un tour
+	advice = AdviseLinks(graph, ants, 1, 2.5, Options{})
+	if advice.Reached || advice.After != 2 {
+		t.Errorf("max distance 2.5 : after %d, reached %v, want 2, false", advice.After, advice.Reached)
+	}
+}
@@ -2,13 +2,17 @@
 package colony
 
 import (
+	"context"
 	"lem-in/modules"
+	"runtime"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 )
 
-func FindAllPaths(room *modules.Room, exit *modules.Room, currentPath []*modules.Room) [][]*modules.Room {
+// Récupère tous les chemins reliant room à exit. Si le contexte est annulé, renvoie les chemins trouvés jusque là.
+func FindAllPaths(ctx context.Context, room *modules.Room, exit *modules.Room, currentPath []*modules.Room) [][]*modules.Room {
 	// Copie le chemin emprunter jusqu'à la salle actuelle pour gérer correctement les modifications de slices.
 	copyPath := append([]*modules.Room{}, currentPath...)
 	// On ajoute la salle actuelle
@@ -25,12 +29,16 @@ func FindAllPaths(room *modules.Room, exit *modules.Room, currentPath []*modules
 
 	// Pour chaque voisin de notre salle
 	for _, neighbour := range room.Neighbours {
+		// Si le temps est écoulé, on arrête l'exploration et on garde ce qui a déjà été trouvé
+		if ctx.Err() != nil {
+			break
+		}
 		// Si la salle a déjà été visitée, on l'ignore (sinon on tourne en rond)
 		if slices.Contains(copyPath, neighbour) {
 			continue
 		}
 		// On récupère récursivement tous les chemins qui relient ce voisin à la sortie
-		subPaths := FindAllPaths(neighbour, exit, copyPath)
+		subPaths := FindAllPaths(ctx, neighbour, exit, copyPath)
 		// Si aucun chemin ne relie ce voisin à la sortie, on ignore cette direction (gestion des impasses)
 		if subPaths == nil {
 			continue
@@ -85,19 +93,32 @@ func OptimizePaths(paths [][]*modules.Room) [][]*modules.Room {
 }
 
 // Récupère toutes les combinaisons de chemin indépendants entre eux (ne partagent aucune salle en dehors de l'entrée/sortie)
-func IndepPaths(paths [][]*modules.Room) [][][]*modules.Room {
+// Si le contexte est annulé, renvoie les combinaisons trouvées jusque là.
+func IndepPaths(ctx context.Context, paths [][]*modules.Room) [][][]*modules.Room {
 	var allSets [][][]*modules.Room
+	exploreIndep(ctx, paths, func(set [][]*modules.Room) {
+		allSets = append(allSets, set)
+	})
+	return allSets
+}
 
+// Parcourt toutes les combinaisons de chemins indépendants et appelle found pour chacune d'elles, dans un ordre déterministe.
+// Chaque combinaison transmise est une copie que found peut conserver.
+func exploreIndep(ctx context.Context, paths [][]*modules.Room, found func([][]*modules.Room)) {
 	// On génère une clé pour chaque groupe de chemin pour l'identifier efficacement quelque soit l'ordre au sein de ce dernier
 	seen := make(map[string]bool)
 
 	var explore func(current [][]*modules.Room, start int)
-	// Explore est une fonction récursive qui va construire les combinaisons et les ajouter à allSets lorsqu'elles sont terminées
-	// Elle est imbriquée dans IndepPaths pour simplifier la gestion de ses arguments.
+	// Explore est une fonction récursive qui va construire les combinaisons et les transmettre à found lorsqu'elles sont terminées
+	// Elle est imbriquée dans exploreIndep pour simplifier la gestion de ses arguments.
 	explore = func(current [][]*modules.Room, start int) {
 		canExtend := false
 
 		for i := start; i < len(paths); i++ {
+			// Le temps est écoulé : la combinaison actuelle n'est pas forcément complète, on ne la garde pas
+			if ctx.Err() != nil {
+				return
+			}
 			compatible := true
 			for _, p := range current {
 				// Test l'indépendance entre chaque chemin et l'ensemble des chemins de la combinaison actuelle
@@ -121,13 +142,12 @@ func IndepPaths(paths [][]*modules.Room) [][][]*modules.Room {
 			key := pathSetKey(current)
 			if !seen[key] {
 				seen[key] = true
-				allSets = append(allSets, append([][]*modules.Room{}, current...))
+				found(append([][]*modules.Room{}, current...))
 			}
 		}
 	}
 	// On appelle explore avec une combinaison vide pour commencer.
 	explore([][]*modules.Room{}, 0)
-	return allSets
 }
 
 // Vérifie que deux chemins ne partagent pas de salle autre que start et end
@@ -164,61 +184,239 @@ func pathSetKey(paths [][]*modules.Room) string {
 }
 
 // Calcule le temps de résolution d'une combinaison de chemins
-func calculateTime(nbAnt int, paths [][]*modules.Room) (int, []int) {
-	// Trie les chemins par longueur
-	sort.Slice(paths, func(i, j int) bool {
-		return len(paths[i]) < len(paths[j])
+// Le résultat est identique à l'envoi des fourmis une par une dans le chemin le plus rapide (à égalité, le plus court),
+// mais se calcule en O(chemins log chemins) quel que soit le nombre de fourmis ("remplissage" des chemins comme des vases).
+// La slice paths n'est pas modifiée : antsPerPath[i] correspond à paths[i], ce qui permet d'évaluer plusieurs combinaisons en parallèle.
+func calculateTime(nbAnt int64, paths [][]*modules.Room) (int64, []int64) {
+	// Trie les indices des chemins par longueur (tri stable : à longueur égale, l'ordre de paths départage)
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(paths[order[i]]) < len(paths[order[j]])
 	})
 
 	// Enregistre le nombre de fourmis à envoyer dans chaque chemin
-	antsPerPath := make([]int, len(paths))
-
-	// Tant qu'il reste des fourmis
-	for nbAnt > 0 {
-		// On regarde quelle chemin est le plus rapide pour cette fourmi là
-		bestIndex := 0
-		bestTime := len(paths[0]) + antsPerPath[0]
-		// Pour chaque chemin, on compare le temps de parcours
-		for i := 1; i < len(paths); i++ {
-			// Le temps de parcours se calcule à partir de la longueur du chemin et le nombre de fourmi déjà envoyé dedans
-			t := len(paths[i]) + antsPerPath[i]
-			// Si t est meilleur que le bestTime jusque maintenant, on enregistre son index et son temps
-			if t < bestTime {
-				bestTime = t
-				bestIndex = i
-			}
+	antsPerPath := make([]int64, len(paths))
+	if nbAnt <= 0 || len(paths) == 0 {
+		return 0, antsPerPath
+	}
+
+	// La j-ième fourmi (à partir de 0) envoyée dans le chemin i arrive au "temps" len(chemin i) + j.
+	// On cherche le plus petit niveau h tel qu'au moins nbAnt fourmis arrivent au temps h ou avant.
+	// Avec les k chemins les plus courts, h = ceil((nbAnt + somme des longueurs) / k) - 1.
+	// On ajoute des chemins tant que le suivant est assez court pour recevoir des fourmis avant h.
+	var sumLen int64
+	var level int64
+	used := 0
+	for used < len(paths) {
+		sumLen += int64(len(paths[order[used]]))
+		used++
+		level = ceilDiv(nbAnt, sumLen, int64(used)) - 1
+		if used == len(paths) || int64(len(paths[order[used]])) > level {
+			break
 		}
-		// On envoie la fourmi dans ce chemin
-		antsPerPath[bestIndex]++
-		nbAnt--
 	}
 
-	// On compare le temps utilisé par chaque chemin pour savoir quand la dernière fourmi arrivera
-	maxTime := 0
-	for i := range paths {
-		t := len(paths[i]) + antsPerPath[i] - 1
-		if t > maxTime {
-			maxTime = t
+	// Chaque chemin utilisé reçoit toutes les fourmis qui arrivent avant h...
+	remaining := nbAnt
+	for _, i := range order[:used] {
+		antsPerPath[i] = max(0, level-int64(len(paths[i])))
+		remaining -= antsPerPath[i]
+	}
+	// ... et les dernières fourmis, qui arrivent exactement à h, vont dans les chemins les plus courts.
+	for _, i := range order[:used] {
+		if remaining == 0 {
+			break
 		}
+		antsPerPath[i]++
+		remaining--
 	}
+	// Si h a été plafonné à math.MaxInt64 (un seul chemin et presque autant de fourmis), le reste va au plus court
+	antsPerPath[order[0]] += remaining
+
+	return level, antsPerPath
+}
+
+// Calcule ceil((a + b) / k) sans dépasser la capacité d'un int64 lorsque a est très grand (b étant petit) :
+// le résultat est plafonné à math.MaxInt64
+func ceilDiv(a, b, k int64) int64 {
+	quotient, rest := a/k, a%k
+	return saturatingAdd(quotient, (rest+b+k-1)/k)
+}
 
-	return maxTime, antsPerPath
+// Options regroupe les réglages de la résolution.
+type Options struct {
+	// Nombre de goroutines qui évaluent les combinaisons de chemins (runtime.GOMAXPROCS si <= 0)
+	Workers int
+	// Critères utilisés pour comparer les combinaisons et répartir les fourmis (Makespan si vide)
+	Objective Objective
 }
 
 // Calcule le temps de résolution de toutes les combinaisons d'une colonie et renvoie la plus rapide
-func Resolve(nbAnt int, colony []*modules.Room) [][]*modules.Room {
-	paths := OptimizePaths(FindAllPaths(colony[0], colony[len(colony)-1], nil))
-	indepPaths := IndepPaths(paths)
-	bestset := indepPaths[0]
-	// On initialise le meilleur temps
-	bestTime, _ := calculateTime(nbAnt, indepPaths[0])
-	for _, set := range indepPaths {
-		// Si une combinaison de chemin est plus rapide à traverser, on la sauvegarde
-		time, _ := calculateTime(nbAnt, set)
-		if time < bestTime {
-			bestset = set
-			bestTime = time
-		}
-	}
-	return bestset
+// (ou la meilleure selon opts.Objective)
+// Si le contexte est annulé avant la fin, renvoie la meilleure combinaison trouvée jusque là et complete vaut false.
+// Renvoie nil si aucun chemin ne relie l'entrée à la sortie.
+func Resolve(ctx context.Context, nbAnt int64, colony []*modules.Room, opts Options) (bestset [][]*modules.Room, complete bool) {
+	// On prépare une solution de secours immédiate, utilisée si la recherche complète n'a pas le temps d'aboutir
+	fallback := greedyIndep(colony[0], colony[len(colony)-1])
+	if fallback == nil {
+		return nil, true
+	}
+
+	var best modules.Solution
+	paths := FindAllPaths(ctx, colony[0], colony[len(colony)-1], nil)
+	if ctx.Err() == nil {
+		bestset, best = evaluateSets(ctx, nbAnt, OptimizePaths(paths), opts)
+	}
+	// Si la recherche a été interrompue, la solution de secours peut être meilleure
+	if bestset == nil || opts.Objective.Less(NewSolution(nbAnt, fallback, false, opts.Objective), best) {
+		bestset = fallback
+	}
+	return bestset, ctx.Err() == nil
+}
+
+// Nombre de combinaisons transmises d'un coup à un worker, pour limiter le coût des channels
+const setsPerBatch = 64
+
+// Évalue en parallèle toutes les combinaisons de chemins indépendants et renvoie la meilleure avec sa solution.
+// Les combinaisons sont numérotées dans l'ordre où IndepPaths les trouve : à égalité, la plus petite numérotée l'emporte,
+// comme dans une évaluation séquentielle. Le résultat ne dépend donc pas du nombre de workers.
+func evaluateSets(ctx context.Context, nbAnt int64, paths [][]*modules.Room, opts Options) ([][]*modules.Room, modules.Solution) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	type batch struct {
+		first int
+		sets  [][][]*modules.Room
+	}
+	type candidate struct {
+		index    int
+		set      [][]*modules.Room
+		solution modules.Solution
+	}
+	// Renvoie true si a est meilleur que b (b.set == nil signifie qu'aucune combinaison n'a encore été évaluée)
+	better := func(a, b candidate) bool {
+		if b.set == nil || opts.Objective.Less(a.solution, b.solution) {
+			return true
+		}
+		return !opts.Objective.Less(b.solution, a.solution) && a.index < b.index
+	}
+
+	batches := make(chan batch, workers)
+	results := make(chan candidate, workers)
+
+	// Une goroutine génère les combinaisons et les distribue par paquets
+	go func() {
+		defer close(batches)
+		current := batch{}
+		index := 0
+		send := func() {
+			select {
+			case batches <- current:
+			case <-ctx.Done():
+			}
+		}
+		exploreIndep(ctx, paths, func(set [][]*modules.Room) {
+			current.sets = append(current.sets, set)
+			index++
+			if len(current.sets) == setsPerBatch {
+				send()
+				current = batch{first: index}
+			}
+		})
+		if len(current.sets) > 0 {
+			send()
+		}
+	}()
+
+	// Chaque worker garde la meilleure combinaison parmi celles qu'il a évaluées
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var best candidate
+			for b := range batches {
+				for i, set := range b.sets {
+					if ctx.Err() != nil {
+						break
+					}
+					solution := NewSolution(nbAnt, set, false, opts.Objective)
+					if c := (candidate{index: b.first + i, set: set, solution: solution}); better(c, best) {
+						best = c
+					}
+				}
+			}
+			results <- best
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	// On réunit les meilleurs résultats de chaque worker
+	var best candidate
+	for c := range results {
+		if c.set != nil && better(c, best) {
+			best = c
+		}
+	}
+	return best.set, best.solution
+}
+
+// Construit rapidement une combinaison de chemins indépendants en prenant à chaque fois le plus court chemin
+// qui n'utilise aucune salle des chemins déjà choisis. Renvoie nil si l'entrée et la sortie ne sont pas reliées.
+func greedyIndep(room *modules.Room, exit *modules.Room) [][]*modules.Room {
+	var set [][]*modules.Room
+	blocked := make(map[*modules.Room]bool)
+	directUsed := false
+	for {
+		path := shortestPathAvoiding(room, exit, blocked, directUsed)
+		if path == nil {
+			return set
+		}
+		set = append(set, path)
+		for _, r := range path[1 : len(path)-1] {
+			blocked[r] = true
+		}
+		// Le lien direct entre l'entrée et la sortie ne peut être emprunté que par un seul chemin
+		if len(path) == 2 {
+			directUsed = true
+		}
+	}
+}
+
+// Renvoie le plus court chemin entre room et exit (parcours en largeur), nil s'il n'existe pas
+func ShortestPath(room *modules.Room, exit *modules.Room) []*modules.Room {
+	return shortestPathAvoiding(room, exit, nil, false)
+}
+
+// Parcours en largeur qui ignore les salles bloquées, et le lien direct entrée-sortie si skipDirect est vrai
+func shortestPathAvoiding(room *modules.Room, exit *modules.Room, blocked map[*modules.Room]bool, skipDirect bool) []*modules.Room {
+	previous := map[*modules.Room]*modules.Room{room: nil}
+	queue := []*modules.Room{room}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == exit {
+			var path []*modules.Room
+			for r := exit; r != nil; r = previous[r] {
+				path = append(path, r)
+			}
+			slices.Reverse(path)
+			return path
+		}
+		for _, neighbour := range current.Neighbours {
+			if blocked[neighbour] || (skipDirect && current == room && neighbour == exit) {
+				continue
+			}
+			if _, seen := previous[neighbour]; !seen {
+				previous[neighbour] = current
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	return nil
 }
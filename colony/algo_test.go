@@ -0,0 +1,114 @@
+package colony
+
+import (
+	"context"
+	"lem-in/modules"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Référence : les fourmis sont envoyées une par une dans le chemin où elles arrivent le plus tôt (à égalité, le plus
+// court, puis le premier). La j-ième fourmi d'un chemin de l salles arrive au "temps" l + j.
+func calculateTimePerAnt(nbAnt int64, paths [][]*modules.Room) (int64, []int64) {
+	antsPerPath := make([]int64, len(paths))
+	var level int64
+	for range nbAnt {
+		best := -1
+		for i, path := range paths {
+			arrival := int64(len(path)) + antsPerPath[i]
+			if best < 0 {
+				best = i
+				continue
+			}
+			bestArrival := int64(len(paths[best])) + antsPerPath[best]
+			if arrival < bestArrival || (arrival == bestArrival && len(path) < len(paths[best])) {
+				best = i
+			}
+		}
+		level = max(level, int64(len(paths[best]))+antsPerPath[best])
+		antsPerPath[best]++
+	}
+	return level, antsPerPath
+}
+
+func TestCalculateTimeMatchesPerAnt(t *testing.T) {
+	for _, lengths := range [][]int{{2}, {5}, {3, 3}, {2, 4}, {4, 2, 3}, {3, 7, 3, 4}, {2, 9, 9, 5, 3}} {
+		paths := make([][]*modules.Room, len(lengths))
+		for i, length := range lengths {
+			paths[i] = make([]*modules.Room, length)
+		}
+		for ants := int64(0); ants <= 40; ants++ {
+			level, antsPerPath := calculateTime(ants, paths)
+			wantLevel, wantAnts := calculateTimePerAnt(ants, paths)
+			if level != wantLevel || !slices.Equal(antsPerPath, wantAnts) {
+				t.Errorf("lengths %v, %d ants : level %d, ants %v ; per ant : level %d, ants %v",
+					lengths, ants, level, antsPerPath, wantLevel, wantAnts)
+			}
+		}
+	}
+}
+
+// Avec un nombre de fourmis proche de la limite des int64, le calcul ne dépend que du nombre de chemins.
+func TestCalculateTimeLargeAnts(t *testing.T) {
+	paths := [][]*modules.Room{make([]*modules.Room, 3), make([]*modules.Room, 4)}
+	ants := int64(math.MaxInt64 - 10)
+	var antsPerPath []int64
+	allocs := testing.AllocsPerRun(10, func() {
+		_, antsPerPath = calculateTime(ants, paths)
+	})
+	if allocs > 10 {
+		t.Errorf("%v allocations", allocs)
+	}
+	if antsPerPath[0]+antsPerPath[1] != ants || antsPerPath[0]-antsPerPath[1] != 1 {
+		t.Errorf("ants per path %v for %d ants", antsPerPath, ants)
+	}
+
+	// Avec un seul chemin, le niveau est plafonné mais toutes les fourmis sont réparties
+	if _, antsPerPath := calculateTime(ants, paths[:1]); antsPerPath[0] != ants {
+		t.Errorf("ants per path %v for %d ants on a single path", antsPerPath, ants)
+	}
+	// Avec un nombre pair de fourmis, une place du dernier niveau reste libre : elle est dans le chemin le plus long
+	ants = math.MaxInt64 - 1
+	if _, antsPerPath := calculateTime(ants, paths); antsPerPath[0] != ants/2+1 || antsPerPath[1] != ants/2-1 {
+		t.Errorf("ants per path %v for %d ants", antsPerPath, ants)
+	}
+}
+
+// Les combinaisons sont réparties entre les workers, mais les égalités sont départagées de la même façon quel que
+// soit leur nombre : la solution ne dépend pas de Workers.
+func TestBruteForceWorkersDeterministic(t *testing.T) {
+	for _, file := range []string{"example01.txt", "example05.txt", "example06.txt"} {
+		graph, ants := loadMap(t, file)
+		for _, objective := range []Objective{nil, {TotalMoves}} {
+			var reference modules.Solution
+			for _, workers := range []int{1, 2, 8} {
+				solution, err := BruteForce{Options{Workers: workers, Objective: objective}}.Solve(context.Background(), graph, ants)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if workers == 1 {
+					reference = solution
+					continue
+				}
+				if pathNames(solution.Paths) != pathNames(reference.Paths) || !slices.Equal(solution.AntsPerPath, reference.AntsPerPath) {
+					t.Errorf("%s, %v, %d workers : %s %v ; 1 worker : %s %v", file, objective, workers,
+						pathNames(solution.Paths), solution.AntsPerPath, pathNames(reference.Paths), reference.AntsPerPath)
+				}
+			}
+		}
+	}
+}
+
+// Noms des salles de chaque chemin, pour comparer des combinaisons.
+func pathNames(paths [][]*modules.Room) string {
+	var names []string
+	for _, path := range paths {
+		for _, room := range path {
+			names = append(names, room.Name)
+		}
+		names = append(names, "|")
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,82 @@
+// Package colony provides lower bounds on the number of turns needed to solve a colony.
+package colony
+
+import (
+	"context"
+	"lem-in/modules"
+)
+
+// Bornes inférieures du nombre de tours nécessaires pour amener toutes les fourmis à la sortie.
+type Bounds struct {
+	// Plus court chemin + ceil(fourmis / nombre maximal de chemins indépendants) - 1 :
+	// la première fourmi ne peut pas arriver avant d'avoir parcouru le plus court chemin, puis il arrive au plus
+	// une fourmi par tour et par chemin indépendant.
+	Simple int64
+	// Borne des flots : min sur k de ceil((fourmis + longueur totale minimale de k chemins indépendants) / k) - 1.
+	// Envoyer les fourmis en continu dans les chemins d'un flot de coût minimal est optimal (flots répétés dans le temps),
+	// cette borne est donc atteinte par la meilleure solution.
+	Flow int64
+}
+
+// Renvoie la plus grande (donc la meilleure) des bornes.
+func (b Bounds) Best() int64 {
+	return max(b.Simple, b.Flow)
+}
+
+// Calcule les bornes inférieures en cherchant, pour chaque k, les k chemins indépendants de longueur totale minimale
+// (flot de coût minimal dans la colonie où chaque salle ne laisse passer qu'une fourmi).
+func LowerBounds(ctx context.Context, graph []*modules.Room, ants int64) (Bounds, error) {
+	network, source, sink := newRoomNetwork(graph)
+	var bounds Bounds
+	var shortest, totalLength, paths int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return Bounds{}, err
+		}
+		length, found := network.augmentCheapest(source, sink)
+		if !found {
+			break
+		}
+		paths++
+		totalLength += length
+		if paths == 1 {
+			shortest = length
+		}
+		if turns := ceilDiv(ants, totalLength, paths) - 1; paths == 1 || turns < bounds.Flow {
+			bounds.Flow = turns
+		}
+	}
+	if paths == 0 {
+		return Bounds{}, ErrNoPath
+	}
+	bounds.Simple = shortest + ceilDiv(ants, 0, paths) - 1
+	return bounds, nil
+}
+
+// Construit le réseau statique de la colonie : chaque salle est coupée en un nœud d'entrée (2i) et un nœud
+// de sortie (2i+1) reliés par une arête de capacité 1, pour que deux chemins ne partagent aucune salle.
+// Chaque tunnel est une arête de capacité 1 et de coût 1 dans chaque sens utile.
+// Renvoie le réseau, la source (sortie de l'entrée) et le puits (entrée de la sortie).
+func newRoomNetwork(graph []*modules.Room) (*flowNetwork, int, int) {
+	index := make(map[*modules.Room]int, len(graph))
+	for i, room := range graph {
+		index[room] = i
+	}
+	entry, exit := 0, len(graph)-1
+	network := newFlowNetwork(2 * len(graph))
+	for i := range graph {
+		if i != entry && i != exit {
+			network.addEdge(2*i, 2*i+1, 1)
+		}
+	}
+	for u, room := range graph {
+		for _, neighbour := range room.Neighbours {
+			v := index[neighbour]
+			// Revenir dans l'entrée ou repartir de la sortie n'a jamais d'intérêt
+			if u != exit && v != entry {
+				network.addCostEdge(2*u+1, 2*v, 1, 1)
+			}
+		}
+	}
+	return network, 2*entry + 1, 2 * exit
+}
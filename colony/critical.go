@@ -0,0 +1,170 @@
+// Package colony provides the robustness analysis of a colony: which rooms and tunnels matter most.
+package colony
+
+import (
+	"lem-in/modules"
+	"sort"
+)
+
+// Criticality décrit l'effet de la suppression d'une salle ou d'un tunnel sur la meilleure solution.
+type Criticality struct {
+	Kind        string // "room" ou "link"
+	Name        string // Nom de la salle, ou "A-B" pour un tunnel
+	Turns       int64  // Nombre optimal de tours sans cet élément (0 si la sortie devient inaccessible)
+	Increase    int64  // Tours supplémentaires par rapport à la colonie complète
+	Unreachable bool   // La sortie n'est plus accessible depuis l'entrée
+	Cut         bool   // Point d'articulation (salle) ou pont (tunnel) : sa suppression coupe le graphe en deux
+}
+
+// Supprime tour à tour chaque tunnel et chaque salle intermédiaire et renvoie leurs effets, des plus critiques
+// (sortie inaccessible, puis plus forte augmentation du nombre de tours) aux moins critiques, ainsi que le nombre
+// de tours de la colonie complète.
+// Un élément qui n'est emprunté par aucun chemin de la meilleure solution ne change pas le nombre de tours : seuls
+// les éléments de ces chemins sont supprimés, avec IncrementalSolver qui réutilise le flot à chaque fois.
+// La colonie reçue n'est pas modifiée.
+func CriticalElements(graph []*modules.Room, ants int64, opts Options) ([]Criticality, int64, error) {
+	rooms := CloneGraph(graph)
+	solver := NewIncrementalSolver(rooms, ants, opts)
+	base, err := solver.Best()
+	if err != nil {
+		return nil, 0, err
+	}
+	usedRooms, usedLinks := make(map[string]bool), make(map[string]bool)
+	for _, path := range base.Paths {
+		for i, room := range path {
+			usedRooms[room.Name] = true
+			if i > 0 {
+				usedLinks[linkName(path[i-1], room)] = true
+				usedLinks[linkName(room, path[i-1])] = true
+			}
+		}
+	}
+	articulations, bridges := cutElements(rooms)
+
+	// Les suppressions modifient les voisins des salles : on relève les tunnels avant de commencer
+	var links [][2]*modules.Room
+	index := make(map[*modules.Room]int, len(rooms))
+	for i, room := range rooms {
+		index[room] = i
+	}
+	for u, room := range rooms {
+		for _, neighbour := range room.Neighbours {
+			if index[neighbour] > u {
+				links = append(links, [2]*modules.Room{room, neighbour})
+			}
+		}
+	}
+
+	var elements []Criticality
+	for _, link := range links {
+		element := Criticality{Kind: "link", Name: linkName(link[0], link[1]), Turns: base.Turns, Cut: bridges[linkName(link[0], link[1])]}
+		if usedLinks[element.Name] {
+			solver.RemoveLink(link[0].Name, link[1].Name)
+			element.setResult(solver, base.Turns)
+			solver.AddLink(link[0].Name, link[1].Name)
+		}
+		elements = append(elements, element)
+	}
+	for _, room := range rooms[1 : len(rooms)-1] {
+		element := Criticality{Kind: "room", Name: room.Name, Turns: base.Turns, Cut: articulations[room.Name]}
+		if usedRooms[room.Name] {
+			// Une salle supprimée puis rajoutée est un nouvel objet : on ne garde que les noms de ses voisins
+			var neighbours []string
+			for _, neighbour := range room.Neighbours {
+				neighbours = append(neighbours, neighbour.Name)
+			}
+			solver.RemoveRoom(room.Name)
+			element.setResult(solver, base.Turns)
+			solver.AddRoom(room.Name, room.Coordinates)
+			for _, neighbour := range neighbours {
+				solver.AddLink(room.Name, neighbour)
+			}
+		}
+		elements = append(elements, element)
+	}
+
+	sort.SliceStable(elements, func(i, j int) bool {
+		a, b := elements[i], elements[j]
+		if a.Unreachable != b.Unreachable {
+			return a.Unreachable
+		}
+		if a.Increase != b.Increase {
+			return a.Increase > b.Increase
+		}
+		return a.Cut && !b.Cut
+	})
+	return elements, base.Turns, nil
+}
+
+// Enregistre la meilleure solution du solveur (sans l'élément supprimé).
+func (c *Criticality) setResult(solver *IncrementalSolver, baseTurns int64) {
+	solution, err := solver.Best()
+	if err != nil {
+		c.Turns, c.Unreachable = 0, true
+		return
+	}
+	c.Turns, c.Increase = solution.Turns, solution.Turns-baseTurns
+}
+
+func linkName(room1, room2 *modules.Room) string {
+	return room1.Name + "-" + room2.Name
+}
+
+// CloneGraph copie les salles et leurs tunnels, pour modifier la colonie sans toucher à l'originale.
+func CloneGraph(graph []*modules.Room) []*modules.Room {
+	clones := make(map[*modules.Room]*modules.Room, len(graph))
+	rooms := make([]*modules.Room, len(graph))
+	for i, room := range graph {
+		rooms[i] = &modules.Room{Name: room.Name, Coordinates: room.Coordinates}
+		clones[room] = rooms[i]
+	}
+	for i, room := range graph {
+		for _, neighbour := range room.Neighbours {
+			rooms[i].Neighbours = append(rooms[i].Neighbours, clones[neighbour])
+		}
+	}
+	return rooms
+}
+
+// Cherche les points d'articulation et les ponts du graphe (algorithme de Tarjan) : low est le plus petit ordre
+// de visite accessible depuis le sous-arbre d'une salle sans repasser par le tunnel qui y mène.
+// Les ponts sont renvoyés sous leurs deux noms ("A-B" et "B-A").
+func cutElements(graph []*modules.Room) (map[string]bool, map[string]bool) {
+	articulations, bridges := make(map[string]bool), make(map[string]bool)
+	order := make(map[*modules.Room]int, len(graph))
+	low := make(map[*modules.Room]int, len(graph))
+	var visit func(room, parent *modules.Room)
+	visit = func(room, parent *modules.Room) {
+		order[room] = len(order) + 1
+		low[room] = order[room]
+		children := 0
+		for _, neighbour := range room.Neighbours {
+			if neighbour == parent {
+				continue
+			}
+			if order[neighbour] != 0 {
+				low[room] = min(low[room], order[neighbour])
+				continue
+			}
+			children++
+			visit(neighbour, room)
+			low[room] = min(low[room], low[neighbour])
+			if low[neighbour] > order[room] {
+				bridges[linkName(room, neighbour)] = true
+				bridges[linkName(neighbour, room)] = true
+			}
+			if parent != nil && low[neighbour] >= order[room] {
+				articulations[room.Name] = true
+			}
+		}
+		if parent == nil && children > 1 {
+			articulations[room.Name] = true
+		}
+	}
+	for _, room := range graph {
+		if order[room] == 0 {
+			visit(room, nil)
+		}
+	}
+	return articulations, bridges
+}
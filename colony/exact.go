@@ -0,0 +1,212 @@
+// Package colony provides an exact solver based on a time-expanded flow network.
+package colony
+
+import (
+	"context"
+	"errors"
+	"lem-in/modules"
+	"sort"
+)
+
+// Exact résout la colonie de manière optimale avec un réseau de flot "déplié dans le temps" : chaque salle existe
+// en un exemplaire par tour et chaque fourmi correspond à une unité de flot. Contrairement à Resolve, les fourmis
+// peuvent attendre ou changer de route, la solution trouvée est donc optimale parmi tous les déplacements possibles.
+// Le réseau contient salles × tours nœuds : ce solveur est réservé aux petites et moyennes colonies.
+type Exact struct {
+	Options Options
+}
+
+// Taille maximale (en nœuds) du réseau déplié, au-delà de laquelle le solveur exact renonce
+const exactMaxNodes = 4_000_000
+
+// Erreur renvoyée lorsque le réseau déplié dans le temps serait trop grand
+var ErrTooLarge = errors.New("Colony too large for the exact solver")
+
+// Seul le dernier tour (Makespan) est minimisé, les autres critères de l'objectif sont ignorés.
+// Cherche par dichotomie le plus petit nombre de tours T pour lequel le flot maximal du réseau déplié sur T tours
+// atteint le nombre de fourmis. Si le contexte est annulé, renvoie la meilleure solution trouvée avec Optimal à false.
+func (e Exact) Solve(ctx context.Context, graph []*modules.Room, ants int64) (modules.Solution, error) {
+	fallback := greedyIndep(graph[0], graph[len(graph)-1])
+	if fallback == nil {
+		return modules.Solution{}, ErrNoPath
+	}
+	// Les chemins indépendants construits rapidement donnent une solution réalisable, donc une borne supérieure
+	best := NewSolution(ants, fallback, false, nil)
+	// Le réseau a 2 × salles × (tours + 1) nœuds : la comparaison par division ne déborde pas, même avec
+	// un nombre de fourmis (donc de tours) proche de la limite des int64
+	if best.Turns > exactMaxNodes/(2*int64(len(graph)))-1 {
+		return modules.Solution{}, ErrTooLarge
+	}
+
+	// Aucune fourmi ne peut arriver avant d'avoir parcouru le plus court chemin (le premier chemin de greedyIndep)
+	low, high := int64(len(fallback[0])-1), best.Turns
+	for low < high {
+		turns := (low + high) / 2
+		network := newTimeNetwork(graph, ants, turns)
+		flow, err := network.maxFlow(ctx, network.source, network.sink, ants)
+		if err != nil {
+			return best, nil
+		}
+		if flow < ants {
+			low = turns + 1
+			continue
+		}
+		high = turns
+		best = modules.Solution{Moves: network.schedule(ants)}
+		best.Turns, best.SumArrival, best.TotalMoves = measureMoves(best.Moves, graph[len(graph)-1])
+	}
+	best.Optimal = true
+	return best, nil
+}
+
+// Réseau de flot déplié dans le temps : chaque salle r au tour t est représentée par un nœud d'entrée et un nœud
+// de sortie reliés par une arête de capacité 1 (une fourmi par salle), sauf pour l'entrée et la sortie.
+type timeNetwork struct {
+	*flowNetwork
+	graph  []*modules.Room
+	turns  int64
+	source int
+	sink   int
+	links  [][]timeLink // Arêtes des tunnels, pour chaque tour
+	waits  [][]int      // Arête "attendre dans la salle" de chaque salle, pour chaque tour
+}
+
+// Les deux sens d'un tunnel entre les tours t et t+1 (-1 si ce sens n'existe pas)
+type timeLink struct {
+	forward, backward int
+}
+
+func newTimeNetwork(graph []*modules.Room, ants int64, turns int64) *timeNetwork {
+	rooms := len(graph)
+	index := make(map[*modules.Room]int, rooms)
+	for i, room := range graph {
+		index[room] = i
+	}
+	entry, exit := 0, rooms-1
+	node := func(room int, turn int64, out int) int {
+		return (int(turn)*rooms+room)*2 + out
+	}
+	// Capacité d'une salle : une fourmi, sauf pour l'entrée et la sortie
+	capacity := func(room int) int64 {
+		if room == entry || room == exit {
+			return ants
+		}
+		return 1
+	}
+
+	network := &timeNetwork{
+		flowNetwork: newFlowNetwork(rooms*2*int(turns+1) + 1),
+		graph:       graph,
+		turns:       turns,
+		source:      rooms * 2 * int(turns+1),
+		sink:        node(exit, turns, 0),
+		links:       make([][]timeLink, turns),
+		waits:       make([][]int, turns),
+	}
+	network.addEdge(network.source, node(entry, 0, 0), ants)
+	for turn := int64(0); turn <= turns; turn++ {
+		for room := range graph {
+			network.addEdge(node(room, turn, 0), node(room, turn, 1), capacity(room))
+		}
+		if turn == turns {
+			break
+		}
+		for room := range graph {
+			network.waits[turn] = append(network.waits[turn], network.addEdge(node(room, turn, 1), node(room, turn+1, 0), capacity(room)))
+		}
+		for u, room := range graph {
+			for _, neighbour := range room.Neighbours {
+				v := index[neighbour]
+				if u > v {
+					continue
+				}
+				// Revenir dans l'entrée ou repartir de la sortie n'a jamais d'intérêt
+				link := timeLink{forward: -1, backward: -1}
+				if u != exit && v != entry {
+					link.forward = network.addEdge(node(u, turn, 1), node(v, turn+1, 0), 1)
+				}
+				if v != exit && u != entry {
+					link.backward = network.addEdge(node(v, turn, 1), node(u, turn+1, 0), 1)
+				}
+				network.links[turn] = append(network.links[turn], link)
+			}
+		}
+	}
+	return network
+}
+
+// Transforme le flot en mouvements : chaque unité de flot est suivie de l'entrée au tour 0 jusqu'au dernier tour.
+// Les fourmis sont numérotées dans l'ordre de leur départ de l'entrée.
+func (n *timeNetwork) schedule(ants int64) [][]modules.Move {
+	n.cancelSwaps()
+	rooms := len(n.graph)
+
+	// Trajet de chaque fourmi : la salle occupée à chaque tour
+	trajectories := make([][]int, 0, ants)
+	for range ants {
+		trajectory := make([]int, 0, n.turns+1)
+		node := n.to[n.head[n.source]]
+		for {
+			if node%2 == 0 {
+				trajectory = append(trajectory, (node/2)%rooms)
+			}
+			if node == n.sink {
+				break
+			}
+			// On emprunte la première arête (ajoutée par newTimeNetwork, donc d'indice pair) qui porte encore du flot
+			for e := n.head[node]; e != -1; e = n.next[e] {
+				if e%2 == 0 && n.flow(e) > 0 {
+					n.capacity[e]++
+					n.capacity[e^1]--
+					node = n.to[e]
+					break
+				}
+			}
+		}
+		trajectories = append(trajectories, trajectory)
+	}
+	departure := func(trajectory []int) int {
+		for turn, room := range trajectory {
+			if room != 0 {
+				return turn
+			}
+		}
+		return len(trajectory)
+	}
+	sort.SliceStable(trajectories, func(i, j int) bool {
+		return departure(trajectories[i]) < departure(trajectories[j])
+	})
+
+	moves := make([][]modules.Move, n.turns)
+	for turn := int64(1); turn <= n.turns; turn++ {
+		for ant, trajectory := range trajectories {
+			if trajectory[turn] != trajectory[turn-1] {
+				moves[turn-1] = append(moves[turn-1], modules.Move{Ant: int64(ant + 1), Room: n.graph[trajectory[turn]]})
+			}
+		}
+	}
+	return moves
+}
+
+// Deux fourmis qui échangent leurs salles par le même tunnel pendant un tour utiliseraient ce tunnel deux fois :
+// comme les fourmis sont interchangeables, on les fait simplement attendre chacune dans sa salle.
+func (n *timeNetwork) cancelSwaps() {
+	rooms := len(n.graph)
+	for turn, links := range n.links {
+		for _, link := range links {
+			if link.forward < 0 || link.backward < 0 {
+				continue
+			}
+			for n.flow(link.forward) > 0 && n.flow(link.backward) > 0 {
+				u := (n.to[link.backward] / 2) % rooms
+				v := (n.to[link.forward] / 2) % rooms
+				n.reroute(link.forward, n.waits[turn][u])
+				n.reroute(link.backward, n.waits[turn][v])
+			}
+		}
+	}
+}
+
+func init() {
+	RegisterSolver("exact", func(opts Options) Solver { return Exact{Options: opts} })
+}
@@ -0,0 +1,105 @@
+package colony
+
+import (
+	"context"
+	"errors"
+	"lem-in/datas"
+	"lem-in/modules"
+	"math"
+	"strings"
+	"testing"
+)
+
+// Construit la colonie d'une carte de files/.
+func loadMap(t *testing.T, name string) ([]*modules.Room, int64) {
+	t.Helper()
+	lines, err := datas.ReadFile("../files/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseMap(t, lines)
+}
+
+// Construit la colonie décrite par des lignes au format lem-in.
+func parseMap(t *testing.T, lines []string) ([]*modules.Room, int64) {
+	t.Helper()
+	filedatas := datas.SaveDatas(lines)
+	datas.CheckErrors(&filedatas)
+	if len(filedatas.Errors) != 0 {
+		t.Fatal(filedatas.Errors)
+	}
+	rooms := CreatRooms(filedatas)
+	CreatColony(filedatas, rooms)
+	return rooms, filedatas.NbAnts
+}
+
+// Vérifie les mouvements d'une solution avec CheckMoves et renvoie le nombre de tours qu'ils utilisent.
+func checkSolution(t *testing.T, name string, graph []*modules.Room, ants int64, solution modules.Solution) int64 {
+	t.Helper()
+	var output strings.Builder
+	if err := WriteSolution(&output, solution); err != nil {
+		t.Fatal(err)
+	}
+	turns, err := CheckMoves(ants, graph, strings.Split(output.String(), "\n"))
+	if err != nil {
+		t.Fatalf("%s : invalid moves : %v", name, err)
+	}
+	if turns != solution.Turns {
+		t.Fatalf("%s : the solution announces %d turns but its moves use %d", name, solution.Turns, turns)
+	}
+	return turns
+}
+
+func TestExactMatchesOtherSolvers(t *testing.T) {
+	tests := []struct {
+		file  string
+		turns int64
+	}{
+		{"example00.txt", 6},
+		{"example01.txt", 8},
+		{"example02.txt", 11},
+		{"example03.txt", 6},
+		{"example04.txt", 6},
+		{"example05.txt", 8},
+		{"example08.txt", 4},
+		{"example09.txt", 5},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			graph, ants := loadMap(t, test.file)
+			ctx := context.Background()
+			exact, err := Exact{}.Solve(ctx, graph, ants)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !exact.Optimal {
+				t.Fatal("exact solution not proven optimal")
+			}
+			if turns := checkSolution(t, "exact", graph, ants, exact); turns != test.turns {
+				t.Fatalf("exact : %d turns, want %d", turns, test.turns)
+			}
+			for _, name := range []string{"bruteforce", "flow"} {
+				solver, err := NewSolver(name, Options{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				solution, err := solver.Solve(ctx, graph, ants)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if turns := checkSolution(t, name, graph, ants, solution); turns != exact.Turns {
+					t.Errorf("%s : %d turns, exact : %d", name, turns, exact.Turns)
+				}
+			}
+		})
+	}
+}
+
+// Avec un nombre de fourmis proche de la limite des int64, le calcul de la taille du réseau ne doit pas déborder.
+func TestExactTooManyAnts(t *testing.T) {
+	graph, _ := loadMap(t, "example00.txt")
+	_, err := Exact{}.Solve(context.Background(), graph, math.MaxInt64-807)
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("got %v, want ErrTooLarge", err)
+	}
+}
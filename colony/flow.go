@@ -0,0 +1,279 @@
+// Package colony provides a max-flow network shared by the flow-based solvers.
+package colony
+
+import (
+	"context"
+	"math"
+)
+
+// Réseau de flot stocké sous forme de listes d'arêtes : l'arête e et son arête inverse e^1 sont toujours ajoutées ensemble.
+type flowNetwork struct {
+	head     []int   // Première arête sortant de chaque nœud (-1 s'il n'y en a pas)
+	next     []int   // Arête suivante partant du même nœud
+	to       []int   // Nœud d'arrivée de chaque arête
+	capacity []int64 // Capacité restante de chaque arête
+	cost     []int64 // Coût d'une unité de flot sur chaque arête (l'arête inverse a le coût opposé)
+	level    []int   // Distance depuis la source (algorithme de Dinic)
+	iter     []int   // Prochaine arête à explorer pour chaque nœud (algorithme de Dinic)
+}
+
+func newFlowNetwork(nodes int) *flowNetwork {
+	network := &flowNetwork{
+		head:  make([]int, nodes),
+		level: make([]int, nodes),
+		iter:  make([]int, nodes),
+	}
+	for i := range network.head {
+		network.head[i] = -1
+	}
+	return network
+}
+
+// Ajoute une arête de capacité donnée et renvoie son indice (l'arête inverse a l'indice suivant).
+func (f *flowNetwork) addEdge(from, to int, capacity int64) int {
+	return f.addCostEdge(from, to, capacity, 0)
+}
+
+// Ajoute une arête de capacité et de coût donnés et renvoie son indice.
+func (f *flowNetwork) addCostEdge(from, to int, capacity, cost int64) int {
+	edge := len(f.to)
+	f.to = append(f.to, to, from)
+	f.capacity = append(f.capacity, capacity, 0)
+	f.cost = append(f.cost, cost, -cost)
+	f.next = append(f.next, f.head[from], f.head[to])
+	f.head[from] = edge
+	f.head[to] = edge + 1
+	return edge
+}
+
+// Flot qui traverse actuellement une arête (c'est la capacité disponible sur son arête inverse).
+func (f *flowNetwork) flow(edge int) int64 {
+	return f.capacity[edge^1]
+}
+
+// Déplace une unité de flot de l'arête from vers l'arête to (qui doivent relier les mêmes extrémités de flot).
+func (f *flowNetwork) reroute(from, to int) {
+	f.capacity[from]++
+	f.capacity[from^1]--
+	f.capacity[to]--
+	f.capacity[to^1]++
+}
+
+// Calcule un flot maximal de source à sink (algorithme de Dinic), en s'arrêtant dès que limit unités passent.
+// Le flot déjà présent dans le réseau est conservé, seul le flot ajouté est renvoyé.
+func (f *flowNetwork) maxFlow(ctx context.Context, source, sink int, limit int64) (int64, error) {
+	var total int64
+	for total < limit {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		if !f.buildLevels(source, sink) {
+			break
+		}
+		copy(f.iter, f.head)
+		for total < limit {
+			pushed := f.augment(source, sink, limit-total)
+			if pushed == 0 {
+				break
+			}
+			total += pushed
+		}
+	}
+	return total, nil
+}
+
+// Parcours en largeur qui calcule la distance de chaque nœud à la source dans le graphe résiduel.
+func (f *flowNetwork) buildLevels(source, sink int) bool {
+	for i := range f.level {
+		f.level[i] = -1
+	}
+	f.level[source] = 0
+	queue := []int{source}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for e := f.head[node]; e != -1; e = f.next[e] {
+			if f.capacity[e] > 0 && f.level[f.to[e]] < 0 {
+				f.level[f.to[e]] = f.level[node] + 1
+				queue = append(queue, f.to[e])
+			}
+		}
+	}
+	return f.level[sink] >= 0
+}
+
+// Pousse du flot le long d'un chemin dont les distances augmentent de 1 à chaque arête.
+func (f *flowNetwork) augment(node, sink int, limit int64) int64 {
+	if node == sink {
+		return limit
+	}
+	for ; f.iter[node] != -1; f.iter[node] = f.next[f.iter[node]] {
+		e := f.iter[node]
+		if f.capacity[e] <= 0 || f.level[f.to[e]] != f.level[node]+1 {
+			continue
+		}
+		if pushed := f.augment(f.to[e], sink, min(limit, f.capacity[e])); pushed > 0 {
+			f.capacity[e] -= pushed
+			f.capacity[e^1] += pushed
+			return pushed
+		}
+	}
+	return 0
+}
+
+// Pousse une unité de flot le long du chemin le moins coûteux du graphe résiduel (algorithme de Bellman-Ford,
+// qui accepte les coûts négatifs des arêtes inverses). Renvoie le coût de ce chemin, ou false si sink est inaccessible.
+// Répété depuis un flot vide, on obtient pour chaque k le flot de k unités de coût minimal.
+func (f *flowNetwork) augmentCheapest(source, sink int) (int64, bool) {
+	dist := make([]int64, len(f.head))
+	previous := make([]int, len(f.head))
+	inQueue := make([]bool, len(f.head))
+	for i := range dist {
+		dist[i] = math.MaxInt64
+		previous[i] = -1
+	}
+	dist[source] = 0
+	queue := []int{source}
+	inQueue[source] = true
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		inQueue[node] = false
+		for e := f.head[node]; e != -1; e = f.next[e] {
+			if f.capacity[e] <= 0 || dist[node]+f.cost[e] >= dist[f.to[e]] {
+				continue
+			}
+			dist[f.to[e]] = dist[node] + f.cost[e]
+			previous[f.to[e]] = e
+			if !inQueue[f.to[e]] {
+				inQueue[f.to[e]] = true
+				queue = append(queue, f.to[e])
+			}
+		}
+	}
+	if dist[sink] == math.MaxInt64 {
+		return 0, false
+	}
+	for node := sink; node != source; node = f.to[previous[node]^1] {
+		f.capacity[previous[node]]--
+		f.capacity[previous[node]^1]++
+	}
+	return dist[sink], true
+}
+
+// Ajoute un nœud au réseau et renvoie son indice.
+func (f *flowNetwork) addNode() int {
+	f.head = append(f.head, -1)
+	f.level = append(f.level, 0)
+	f.iter = append(f.iter, 0)
+	return len(f.head) - 1
+}
+
+// Supprime une arête (et son inverse) en annulant sa capacité. Le flot qui la traverse doit avoir été retiré avant.
+func (f *flowNetwork) disableEdge(edge int) {
+	f.capacity[edge] = 0
+	f.capacity[edge^1] = 0
+}
+
+// Retire une unité de flot passant par l'arête edge : on remonte le flot jusqu'à la source et on le suit jusqu'au puits.
+// Le flot ne doit contenir aucun cycle (c'est le cas d'un flot de coût minimal lorsque tous les cycles ont un coût positif).
+func (f *flowNetwork) removeUnit(edge, source, sink int) {
+	f.capacity[edge]++
+	f.capacity[edge^1]--
+	// Vers la source : on cherche une arête entrante qui porte du flot (son inverse part du nœud courant)
+	for node := f.to[edge^1]; node != source; {
+		for e := f.head[node]; e != -1; e = f.next[e] {
+			if e%2 == 1 && f.flow(e^1) > 0 {
+				f.capacity[e^1]++
+				f.capacity[e]--
+				node = f.to[e]
+				break
+			}
+		}
+	}
+	// Vers le puits : on suit les arêtes sortantes qui portent du flot
+	for node := f.to[edge]; node != sink; {
+		for e := f.head[node]; e != -1; e = f.next[e] {
+			if e%2 == 0 && f.flow(e) > 0 {
+				f.capacity[e]++
+				f.capacity[e^1]--
+				node = f.to[e]
+				break
+			}
+		}
+	}
+}
+
+// Valeur du flot : somme du flot sur les arêtes qui partent de la source.
+func (f *flowNetwork) flowValue(source int) int64 {
+	var value int64
+	for e := f.head[source]; e != -1; e = f.next[e] {
+		if e%2 == 0 {
+			value += f.flow(e)
+		}
+	}
+	return value
+}
+
+// Coût total du flot.
+func (f *flowNetwork) flowCost() int64 {
+	var total int64
+	for e := 0; e < len(f.to); e += 2 {
+		total += f.flow(e) * f.cost[e]
+	}
+	return total
+}
+
+// Tant que le graphe résiduel contient un cycle de coût négatif, fait circuler une unité de flot le long de ce cycle.
+// La valeur du flot ne change pas mais son coût baisse : à la fin, le flot est de coût minimal pour sa valeur.
+func (f *flowNetwork) cancelNegativeCycles() {
+	for {
+		cycle := f.negativeCycle()
+		if cycle == nil {
+			return
+		}
+		for _, e := range cycle {
+			f.capacity[e]--
+			f.capacity[e^1]++
+		}
+	}
+}
+
+// Cherche un cycle de coût négatif (algorithme de Bellman-Ford depuis tous les nœuds à la fois) et renvoie ses arêtes.
+func (f *flowNetwork) negativeCycle() []int {
+	dist := make([]int64, len(f.head))
+	previous := make([]int, len(f.head))
+	for i := range previous {
+		previous[i] = -1
+	}
+	last := -1
+	for range len(f.head) {
+		last = -1
+		for e := range f.to {
+			from := f.to[e^1]
+			if f.capacity[e] > 0 && dist[from]+f.cost[e] < dist[f.to[e]] {
+				dist[f.to[e]] = dist[from] + f.cost[e]
+				previous[f.to[e]] = e
+				last = f.to[e]
+			}
+		}
+		if last < 0 {
+			return nil
+		}
+	}
+	// Un nœud encore amélioré au dernier passage mène à un cycle négatif : on remonte assez loin pour être dedans
+	node := last
+	for range len(f.head) {
+		node = f.to[previous[node]^1]
+	}
+	var cycle []int
+	for current := node; ; {
+		e := previous[current]
+		cycle = append(cycle, e)
+		current = f.to[e^1]
+		if current == node {
+			break
+		}
+	}
+	return cycle
+}
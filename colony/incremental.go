@@ -0,0 +1,290 @@
+// Package colony provides a flow-based solver that can be updated after edits of the colony.
+package colony
+
+import (
+	"context"
+	"errors"
+	"lem-in/modules"
+	"math/bits"
+)
+
+// IncrementalSolver garde en mémoire le flot de coût minimal de la colonie (k chemins indépendants de longueur
+// totale minimale) et le met à jour après chaque modification au lieu de tout recalculer.
+// Le nombre de chemins k est choisi pour minimiser (fourmis + longueur totale) / k, dont le plafond moins un est le
+// nombre de tours optimal (voir Bounds.Flow) : la meilleure combinaison est donc toujours disponible avec Best.
+// Les modifications portent directement sur les salles reçues (voisins et liste des salles).
+type IncrementalSolver struct {
+	Options  Options
+	rooms    []*modules.Room
+	ants     int64
+	network  *flowNetwork
+	source   int
+	sink     int
+	id       map[*modules.Room]int    // Numéro de chaque salle dans le réseau (nœuds 2*id et 2*id+1)
+	roomOf   []*modules.Room          // Salle correspondant à chaque numéro (nil si elle a été supprimée)
+	internal map[*modules.Room]int    // Arête d'entrée à sortie de chaque salle intermédiaire (capacité 1)
+	tunnels  map[[2]*modules.Room]int // Arête de chaque sens utile d'un tunnel
+	disabled map[[2]*modules.Room]int // Arêtes des tunnels supprimés, réutilisées si le tunnel est rajouté
+}
+
+// Créer le solveur et calcule la première solution. graph contient l'entrée en premier et la sortie en dernier.
+func NewIncrementalSolver(graph []*modules.Room, ants int64, opts Options) *IncrementalSolver {
+	s := &IncrementalSolver{
+		Options:  opts,
+		rooms:    append([]*modules.Room{}, graph...),
+		ants:     ants,
+		network:  newFlowNetwork(0),
+		id:       make(map[*modules.Room]int, len(graph)),
+		internal: make(map[*modules.Room]int, len(graph)),
+		tunnels:  make(map[[2]*modules.Room]int),
+		disabled: make(map[[2]*modules.Room]int),
+	}
+	for _, room := range graph {
+		s.addNodes(room)
+	}
+	s.source, s.sink = 2*s.id[graph[0]]+1, 2*s.id[graph[len(graph)-1]]
+	for _, room := range graph {
+		for _, neighbour := range room.Neighbours {
+			s.addTunnel(room, neighbour)
+		}
+	}
+	s.adjustPaths()
+	return s
+}
+
+// Renvoie les salles de la colonie, entrée en premier et sortie en dernier.
+func (s *IncrementalSolver) Rooms() []*modules.Room {
+	return s.rooms
+}
+
+// Renvoie la meilleure solution pour la colonie et le nombre de fourmis actuels.
+func (s *IncrementalSolver) Best() (modules.Solution, error) {
+	paths := s.paths()
+	if len(paths) == 0 {
+		return modules.Solution{}, ErrNoPath
+	}
+	return NewSolution(s.ants, paths, true, s.Options.Objective), nil
+}
+
+// Change le nombre de fourmis : seul le nombre de chemins peut changer.
+func (s *IncrementalSolver) SetAnts(ants int64) {
+	s.ants = ants
+	s.adjustPaths()
+}
+
+// Ajoute un tunnel entre deux salles existantes.
+func (s *IncrementalSolver) AddLink(name1, name2 string) error {
+	room1, room2, err := s.roomPair(name1, name2)
+	if err != nil {
+		return err
+	}
+	if containsRoom(room1.Neighbours, room2) {
+		return errors.New("Link already exists : " + name1 + "-" + name2)
+	}
+	addLink(room1, room2)
+	s.addTunnel(room1, room2)
+	s.addTunnel(room2, room1)
+	s.reoptimize()
+	return nil
+}
+
+// Supprime le tunnel entre deux salles.
+func (s *IncrementalSolver) RemoveLink(name1, name2 string) error {
+	room1, room2, err := s.roomPair(name1, name2)
+	if err != nil {
+		return err
+	}
+	if !containsRoom(room1.Neighbours, room2) {
+		return errors.New("Unknown link : " + name1 + "-" + name2)
+	}
+	s.removeTunnels(room1, room2)
+	s.reoptimize()
+	return nil
+}
+
+// Ajoute une salle sans tunnel (juste avant la sortie, qui reste la dernière salle).
+func (s *IncrementalSolver) AddRoom(name string, coordinates modules.Point) error {
+	if GetRoomByName(name, s.rooms) != nil {
+		return errors.New("Room already exists : " + name)
+	}
+	room := &modules.Room{Name: name, Coordinates: coordinates}
+	exit := s.rooms[len(s.rooms)-1]
+	s.rooms = append(s.rooms[:len(s.rooms)-1], room, exit)
+	s.addNodes(room)
+	return nil
+}
+
+// Supprime une salle intermédiaire et tous ses tunnels.
+func (s *IncrementalSolver) RemoveRoom(name string) error {
+	room := GetRoomByName(name, s.rooms)
+	if room == nil {
+		return errors.New("Unknown room : " + name)
+	}
+	if room == s.rooms[0] || room == s.rooms[len(s.rooms)-1] {
+		return errors.New("Cannot remove the start or the end room : " + name)
+	}
+	for len(room.Neighbours) > 0 {
+		s.removeTunnels(room, room.Neighbours[0])
+	}
+	s.network.disableEdge(s.internal[room])
+	s.roomOf[s.id[room]] = nil
+	delete(s.internal, room)
+	delete(s.id, room)
+	for i, r := range s.rooms {
+		if r == room {
+			s.rooms = append(s.rooms[:i], s.rooms[i+1:]...)
+			break
+		}
+	}
+	s.reoptimize()
+	return nil
+}
+
+func (s *IncrementalSolver) roomPair(name1, name2 string) (*modules.Room, *modules.Room, error) {
+	room1, room2 := GetRoomByName(name1, s.rooms), GetRoomByName(name2, s.rooms)
+	if room1 == nil {
+		return nil, nil, errors.New("Unknown room : " + name1)
+	}
+	if room2 == nil {
+		return nil, nil, errors.New("Unknown room : " + name2)
+	}
+	if room1 == room2 {
+		return nil, nil, errors.New("A room cannot be linked to itself : " + name1)
+	}
+	return room1, room2, nil
+}
+
+// Ajoute les deux nœuds d'une salle, reliés par une arête de capacité 1 sauf pour l'entrée et la sortie.
+func (s *IncrementalSolver) addNodes(room *modules.Room) {
+	id := len(s.roomOf)
+	s.id[room] = id
+	s.roomOf = append(s.roomOf, room)
+	s.network.addNode()
+	s.network.addNode()
+	if len(s.rooms) > 0 && room != s.rooms[0] && room != s.rooms[len(s.rooms)-1] {
+		s.internal[room] = s.network.addEdge(2*id, 2*id+1, 1)
+	}
+}
+
+// Ajoute l'arête du tunnel from -> to, sauf si elle revient dans l'entrée ou repart de la sortie.
+func (s *IncrementalSolver) addTunnel(from, to *modules.Room) {
+	if from == s.rooms[len(s.rooms)-1] || to == s.rooms[0] {
+		return
+	}
+	key := [2]*modules.Room{from, to}
+	if edge, exists := s.disabled[key]; exists {
+		s.network.capacity[edge] = 1
+		s.tunnels[key] = edge
+		delete(s.disabled, key)
+		return
+	}
+	s.tunnels[key] = s.network.addCostEdge(2*s.id[from]+1, 2*s.id[to], 1, 1)
+}
+
+// Supprime les deux sens d'un tunnel, en retirant d'abord le chemin du flot qui l'emprunte.
+// Les arêtes désactivées restent dans le réseau avec une capacité nulle, prêtes à resservir si le tunnel est rajouté.
+func (s *IncrementalSolver) removeTunnels(room1, room2 *modules.Room) {
+	for _, key := range [][2]*modules.Room{{room1, room2}, {room2, room1}} {
+		edge, exists := s.tunnels[key]
+		if !exists {
+			continue
+		}
+		if s.network.flow(edge) > 0 {
+			s.network.removeUnit(edge, s.source, s.sink)
+		}
+		s.network.disableEdge(edge)
+		delete(s.tunnels, key)
+		s.disabled[key] = edge
+	}
+	room1.Neighbours = removeRoom(room1.Neighbours, room2)
+	room2.Neighbours = removeRoom(room2.Neighbours, room1)
+}
+
+func removeRoom(rooms []*modules.Room, target *modules.Room) []*modules.Room {
+	for i, r := range rooms {
+		if r == target {
+			return append(rooms[:i], rooms[i+1:]...)
+		}
+	}
+	return rooms
+}
+
+// Après une modification, le flot reste valide mais n'est plus forcément de coût minimal : on annule les cycles
+// de coût négatif puis on ajuste le nombre de chemins.
+func (s *IncrementalSolver) reoptimize() {
+	s.network.cancelNegativeCycles()
+	s.adjustPaths()
+}
+
+// Ajoute ou retire des chemins un par un tant que (fourmis + longueur totale) / k diminue. Cette fonction de k
+// diminue puis augmente (les longueurs ajoutées par chaque nouveau chemin ne diminuent jamais), on s'arrête donc au minimum.
+func (s *IncrementalSolver) adjustPaths() {
+	paths, length := s.network.flowValue(s.source), s.network.flowCost()
+	added := false
+	for {
+		cost, found := s.network.augmentCheapest(s.source, s.sink)
+		if !found {
+			break
+		}
+		if paths > 0 && !lessRatio(s.ants+length+cost, paths+1, s.ants+length, paths) {
+			s.network.augmentCheapest(s.sink, s.source)
+			break
+		}
+		paths, length, added = paths+1, length+cost, true
+	}
+	for !added && paths > 1 {
+		// Le chemin le moins coûteux du puits vers la source retire le chemin qui fait le plus baisser la longueur totale
+		cost, _ := s.network.augmentCheapest(s.sink, s.source)
+		if !lessRatio(s.ants+length+cost, paths-1, s.ants+length, paths) {
+			s.network.augmentCheapest(s.source, s.sink)
+			break
+		}
+		paths, length = paths-1, length+cost
+	}
+}
+
+// Compare a/b et c/d (nombres positifs) sans débordement : a*d < c*b sur 128 bits.
+func lessRatio(a, b, c, d int64) bool {
+	highLeft, lowLeft := bits.Mul64(uint64(a), uint64(d))
+	highRight, lowRight := bits.Mul64(uint64(c), uint64(b))
+	return highLeft < highRight || (highLeft == highRight && lowLeft < lowRight)
+}
+
+// Décompose le flot en chemins de l'entrée à la sortie, sans le modifier.
+func (s *IncrementalSolver) paths() [][]*modules.Room {
+	used := make([]int64, len(s.network.to))
+	var paths [][]*modules.Room
+	for range s.network.flowValue(s.source) {
+		path := []*modules.Room{s.rooms[0]}
+		for node := s.source; node != s.sink; {
+			for e := s.network.head[node]; e != -1; e = s.network.next[e] {
+				if e%2 == 0 && s.network.flow(e) > used[e] {
+					used[e]++
+					node = s.network.to[e]
+					// Les arêtes des tunnels arrivent sur le nœud d'entrée (pair) d'une salle
+					if node%2 == 0 {
+						path = append(path, s.roomOf[node/2])
+					}
+					break
+				}
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Flow résout la colonie avec IncrementalSolver, sans modification : k chemins indépendants de longueur totale
+// minimale, avec k optimal. Le résultat est optimal parmi les solutions qui envoient les fourmis par des chemins
+// indépendants. La résolution est polynomiale, le contexte n'est donc pas utilisé.
+type Flow struct {
+	Options Options
+}
+
+func (f Flow) Solve(ctx context.Context, graph []*modules.Room, ants int64) (modules.Solution, error) {
+	return NewIncrementalSolver(graph, ants, f.Options).Best()
+}
+
+func init() {
+	RegisterSolver("flow", func(opts Options) Solver { return Flow{Options: opts} })
+}
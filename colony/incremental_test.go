@@ -0,0 +1,116 @@
+package colony
+
+import (
+	"context"
+	"errors"
+	"lem-in/datas"
+	"lem-in/modules"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Après chaque modification, la solution de IncrementalSolver doit valoir celle de Flow sur la colonie réécrite et
+// relue depuis zéro.
+func TestIncrementalMatchesFlow(t *testing.T) {
+	graph, ants := loadMap(t, "example01.txt")
+	solver := NewIncrementalSolver(graph, ants, Options{})
+	steps := []struct {
+		name   string
+		update func() error
+	}{
+		{"initial", func() error { return nil }},
+		{"1000 ants", func() error { solver.SetAnts(1000); return nil }},
+		{"add start-k", func() error { return solver.AddLink("start", "k") }},
+		{"remove h-n", func() error { return solver.RemoveLink("h", "n") }},
+		{"1 ant", func() error { solver.SetAnts(1); return nil }},
+		{"add room x", func() error { return solver.AddRoom("x", modules.Point{X: 3, Y: 3}) }},
+		{"add 0-x", func() error { return solver.AddLink("0", "x") }},
+		{"add x-end", func() error { return solver.AddLink("x", "end") }},
+		{"50 ants", func() error { solver.SetAnts(50); return nil }},
+		{"remove room k", func() error { return solver.RemoveRoom("k") }},
+		{"add h-n", func() error { return solver.AddLink("h", "n") }},
+		{"remove e-end", func() error { return solver.RemoveLink("e", "end") }},
+		{"remove m-end", func() error { return solver.RemoveLink("m", "end") }},
+		{"remove x-end", func() error { return solver.RemoveLink("x", "end") }},
+		{"add A-end", func() error { return solver.AddLink("A", "end") }},
+		{"7 ants", func() error { solver.SetAnts(7); return nil }},
+	}
+	for _, step := range steps {
+		if err := step.update(); err != nil {
+			t.Fatalf("%s : %v", step.name, err)
+		}
+		var colony strings.Builder
+		if err := WriteColony(&colony, solver.ants, solver.Rooms()); err != nil {
+			t.Fatal(err)
+		}
+		fresh, freshAnts := parseMap(t, strings.Split(strings.TrimSpace(colony.String()), "\n"))
+		want, wantErr := Flow{}.Solve(context.Background(), fresh, freshAnts)
+		got, err := solver.Best()
+		if wantErr != nil || err != nil {
+			if !errors.Is(err, wantErr) {
+				t.Fatalf("%s : got error %v, want %v", step.name, err, wantErr)
+			}
+			continue
+		}
+		checkSolution(t, step.name, solver.Rooms(), freshAnts, got)
+		if got.Turns != want.Turns || got.TotalMoves != want.TotalMoves {
+			t.Errorf("%s : %d turns and %d moves, fresh solve : %d turns and %d moves", step.name, got.Turns, got.TotalMoves, want.Turns, want.TotalMoves)
+		}
+	}
+}
+
+// Compare une modification suivie de Best avec la même modification suivie d'une résolution complète par Flow.
+// Chaque itération ajoute puis retire un tunnel entre deux salles non reliées de la colonie.
+func BenchmarkIncrementalSolver(b *testing.B) {
+	for _, file := range []string{"example05.txt", "example07.txt"} {
+		lines, err := datas.ReadFile("../files/" + file)
+		if err != nil {
+			b.Fatal(err)
+		}
+		filedatas := datas.SaveDatas(lines)
+		graph := CreatRooms(filedatas)
+		CreatColony(filedatas, graph)
+		// Deux salles intermédiaires non reliées
+		var room1, room2 *modules.Room
+		for _, room := range graph[1 : len(graph)-1] {
+			for _, other := range graph[1 : len(graph)-1] {
+				if room1 == nil && room != other && !slices.Contains(room.Neighbours, other) {
+					room1, room2 = room, other
+				}
+			}
+		}
+
+		b.Run(file+"/incremental", func(b *testing.B) {
+			solver := NewIncrementalSolver(CloneGraph(graph), filedatas.NbAnts, Options{})
+			for b.Loop() {
+				for _, edit := range []func(string, string) error{solver.AddLink, solver.RemoveLink} {
+					if err := edit(room1.Name, room2.Name); err != nil {
+						b.Fatal(err)
+					}
+					if _, err := solver.Best(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(file+"/fresh", func(b *testing.B) {
+			rooms := CloneGraph(graph)
+			first, second := GetRoomByName(room1.Name, rooms), GetRoomByName(room2.Name, rooms)
+			for b.Loop() {
+				for _, linked := range []bool{true, false} {
+					if linked {
+						first.Neighbours = append(first.Neighbours, second)
+						second.Neighbours = append(second.Neighbours, first)
+					} else {
+						first.Neighbours = first.Neighbours[:len(first.Neighbours)-1]
+						second.Neighbours = second.Neighbours[:len(second.Neighbours)-1]
+					}
+					if _, err := (Flow{}).Solve(context.Background(), rooms, filedatas.NbAnts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
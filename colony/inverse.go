@@ -0,0 +1,71 @@
+// Package colony provides the inverse problem: how many ants can reach the end within a number of turns.
+package colony
+
+import (
+	"context"
+	"errors"
+	"lem-in/modules"
+	"strconv"
+)
+
+// Renvoie le nombre maximal de fourmis qui peuvent atteindre la sortie en turns tours au plus, avec la solution
+// correspondante (Solution.Turns <= turns). Toutes les combinaisons de IndepPaths sont évaluées : à égalité de fourmis,
+// la meilleure selon opts.Objective parmi les solutions qui tiennent en turns tours l'emporte. Si le contexte est
+// annulé, la meilleure combinaison trouvée jusque là est utilisée et Optimal vaut false.
+func MaxAnts(ctx context.Context, graph []*modules.Room, turns int64, opts Options) (int64, modules.Solution, error) {
+	var best [][]*modules.Room
+	var bestAnts int64
+	var bestSolution modules.Solution
+	paths := OptimizePaths(FindAllPaths(ctx, graph[0], graph[len(graph)-1], nil))
+	exploreIndep(ctx, paths, func(set [][]*modules.Room) {
+		ants := antsWithin(turns, set)
+		if ants == 0 || ants < bestAnts {
+			return
+		}
+		solution := solutionWithin(ants, set, turns, opts.Objective)
+		if ants > bestAnts || opts.Objective.Less(solution, bestSolution) {
+			best, bestAnts, bestSolution = set, ants, solution
+		}
+	})
+	// Recherche interrompue : la combinaison construite rapidement peut faire mieux
+	if ctx.Err() != nil {
+		if fallback := greedyIndep(graph[0], graph[len(graph)-1]); fallback != nil && antsWithin(turns, fallback) > bestAnts {
+			bestAnts = antsWithin(turns, fallback)
+			best, bestSolution = fallback, solutionWithin(bestAnts, fallback, turns, opts.Objective)
+		}
+	}
+	if best == nil {
+		if ShortestPath(graph[0], graph[len(graph)-1]) == nil {
+			return 0, modules.Solution{}, ErrNoPath
+		}
+		return 0, modules.Solution{}, errors.New("No ant can reach the end within " + strconv.FormatInt(turns, 10) + " turns")
+	}
+	bestSolution.Optimal = ctx.Err() == nil
+	return bestAnts, bestSolution, nil
+}
+
+// Construit la solution d'une combinaison pour les ants fourmis qu'elle amène à la sortie en turns tours. La
+// répartition de calculateTime (dernier tour minimal) tient toujours dans ce délai ; les autres répartitions de
+// assignAnts ne la remplacent que si elles y tiennent aussi : l'objectif ne fait que départager.
+func solutionWithin(ants int64, paths [][]*modules.Room, turns int64, objective Objective) modules.Solution {
+	solution := NewSolution(ants, paths, true, nil)
+	level, _ := calculateTime(ants, solution.Paths)
+	for _, candidate := range [][]int64{fillShortestFirst(ants, solution.Paths, level), fillShortestOnly(ants, solution.Paths)} {
+		measured := measuredSolution(solution.Paths, candidate)
+		if measured.Turns <= turns && objective.Less(measured, solution) {
+			solution.AntsPerPath = candidate
+			solution.Turns, solution.SumArrival, solution.TotalMoves = measured.Turns, measured.SumArrival, measured.TotalMoves
+		}
+	}
+	return solution
+}
+
+// Nombre de fourmis qu'une combinaison amène à la sortie en turns tours : avec le niveau turns + 1 de calculateTime,
+// chaque chemin de l salles reçoit turns - l + 2 fourmis (la dernière arrive au tour l - 1 + turns - l + 1 = turns).
+func antsWithin(turns int64, paths [][]*modules.Room) int64 {
+	var ants int64
+	for _, path := range paths {
+		ants = saturatingAdd(ants, max(0, turns-int64(len(path))+2))
+	}
+	return ants
+}
@@ -0,0 +1,43 @@
+package colony
+
+import (
+	"context"
+	"testing"
+)
+
+// Deux chemins : start-a-end (2 tunnels) et start-b-c-end (3 tunnels).
+var twoPathsMap = []string{
+	"1",
+	"##start",
+	"start 0 0",
+	"a 1 0",
+	"b 1 2",
+	"c 2 2",
+	"##end",
+	"end 3 0",
+	"start-a",
+	"a-end",
+	"start-b",
+	"b-c",
+	"c-end",
+}
+
+func TestMaxAntsWithinBudget(t *testing.T) {
+	graph, _ := parseMap(t, twoPathsMap)
+	for _, objective := range []Objective{nil, {TotalMoves}, {SumArrival, TotalMoves}} {
+		for turns := int64(2); turns <= 10; turns++ {
+			ants, solution, err := MaxAnts(context.Background(), graph, turns, Options{Objective: objective})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Le chemin de 2 tunnels amène turns - 1 fourmis, celui de 3 tunnels turns - 2
+			if want := 2*turns - 3; ants != want {
+				t.Errorf("%v, %d turns : %d ants, want %d", objective, turns, ants, want)
+			}
+			if solution.Turns > turns {
+				t.Errorf("%v, %d turns : the solution takes %d turns", objective, turns, solution.Turns)
+			}
+			checkSolution(t, objective.String(), graph, ants, solution)
+		}
+	}
+}
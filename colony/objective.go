@@ -0,0 +1,200 @@
+// Package colony provides the objectives used to compare solutions.
+package colony
+
+import (
+	"errors"
+	"lem-in/modules"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Criterion est une quantité à minimiser.
+type Criterion int
+
+const (
+	Makespan   Criterion = iota // Tour d'arrivée de la dernière fourmi
+	SumArrival                  // Somme des tours d'arrivée de toutes les fourmis
+	TotalMoves                  // Nombre total de déplacements
+)
+
+// Noms des critères utilisés en ligne de commande
+var criterionNames = map[Criterion]string{
+	Makespan:   "makespan",
+	SumArrival: "arrival",
+	TotalMoves: "moves",
+}
+
+// Objective est une liste de critères comparés dans l'ordre (ordre lexicographique) : le deuxième critère ne sert
+// qu'à départager les solutions égales sur le premier, etc. Un objectif vide équivaut à Makespan seul.
+type Objective []Criterion
+
+// Lit un objectif sous la forme "makespan,moves".
+func ParseObjective(str string) (Objective, error) {
+	var objective Objective
+	for _, name := range strings.Split(str, ",") {
+		found := false
+		for criterion, criterionName := range criterionNames {
+			if strings.TrimSpace(name) == criterionName {
+				objective = append(objective, criterion)
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.New("Unknown objective : " + name + " (available : makespan, arrival, moves)")
+		}
+	}
+	return objective, nil
+}
+
+func (o Objective) String() string {
+	var names []string
+	for _, criterion := range o.criteria() {
+		names = append(names, criterionNames[criterion])
+	}
+	return strings.Join(names, ",")
+}
+
+// Renvoie les critères de l'objectif, Makespan si aucun n'est donné.
+func (o Objective) criteria() []Criterion {
+	if len(o) == 0 {
+		return []Criterion{Makespan}
+	}
+	return o
+}
+
+// Renvoie true si la solution a est strictement meilleure que b pour cet objectif.
+func (o Objective) Less(a, b modules.Solution) bool {
+	for _, criterion := range o.criteria() {
+		valueA, valueB := criterionValue(a, criterion), criterionValue(b, criterion)
+		if valueA != valueB {
+			return valueA < valueB
+		}
+	}
+	return false
+}
+
+func criterionValue(solution modules.Solution, criterion Criterion) int64 {
+	switch criterion {
+	case SumArrival:
+		return solution.SumArrival
+	case TotalMoves:
+		return solution.TotalMoves
+	default:
+		return solution.Turns
+	}
+}
+
+// Répartit les fourmis dans les chemins selon l'objectif. antsPerPath[i] correspond à paths[i].
+// Trois répartitions sont candidates, on garde la meilleure pour l'objectif complet :
+//   - le remplissage de calculateTime, qui minimise à la fois le dernier tour et la somme des arrivées ;
+//   - le remplissage des chemins les plus courts d'abord, jusqu'à la limite imposée par ce même dernier tour,
+//     qui garde le dernier tour minimal en réduisant souvent le nombre de déplacements ;
+//   - le remplissage des seuls chemins de longueur minimale, qui minimise le nombre de déplacements.
+func (o Objective) assignAnts(nbAnt int64, paths [][]*modules.Room) []int64 {
+	level, best := calculateTime(nbAnt, paths)
+	bestSolution := measuredSolution(paths, best)
+	for _, candidate := range [][]int64{fillShortestFirst(nbAnt, paths, level), fillShortestOnly(nbAnt, paths)} {
+		if solution := measuredSolution(paths, candidate); o.Less(solution, bestSolution) {
+			best, bestSolution = candidate, solution
+		}
+	}
+	return best
+}
+
+// Construit une solution contenant uniquement les mesures d'une répartition, pour la comparer avec Less.
+func measuredSolution(paths [][]*modules.Room, antsPerPath []int64) modules.Solution {
+	var solution modules.Solution
+	solution.Turns, solution.SumArrival, solution.TotalMoves = measure(paths, antsPerPath)
+	return solution
+}
+
+// Chaque chemin peut recevoir level - len + 1 fourmis sans dépasser le niveau de calculateTime : on remplit les plus courts d'abord.
+func fillShortestFirst(nbAnt int64, paths [][]*modules.Room, level int64) []int64 {
+	order := make([]int, len(paths))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(paths[order[i]]) < len(paths[order[j]])
+	})
+	antsPerPath := make([]int64, len(paths))
+	remaining := nbAnt
+	for _, i := range order {
+		antsPerPath[i] = min(remaining, max(0, level-int64(len(paths[i]))+1))
+		remaining -= antsPerPath[i]
+	}
+	return antsPerPath
+}
+
+// Répartit les fourmis entre les seuls chemins de longueur minimale, comme dans calculateTime.
+func fillShortestOnly(nbAnt int64, paths [][]*modules.Room) []int64 {
+	shortest := math.MaxInt
+	for _, path := range paths {
+		shortest = min(shortest, len(path))
+	}
+	var kept [][]*modules.Room
+	var keptIndex []int
+	for i, path := range paths {
+		if len(path) == shortest {
+			kept = append(kept, path)
+			keptIndex = append(keptIndex, i)
+		}
+	}
+	_, keptAnts := calculateTime(nbAnt, kept)
+	antsPerPath := make([]int64, len(paths))
+	for i, ants := range keptAnts {
+		antsPerPath[keptIndex[i]] = ants
+	}
+	return antsPerPath
+}
+
+// Calcule le dernier tour, la somme des tours d'arrivée et le nombre de déplacements d'une répartition.
+// La j-ième fourmi (à partir de 0) d'un chemin de l salles arrive au tour l - 1 + j après l - 1 déplacements.
+// Les sommes sont plafonnées à math.MaxInt64 pour les très grands nombres de fourmis.
+func measure(paths [][]*modules.Room, antsPerPath []int64) (turns, sumArrival, totalMoves int64) {
+	for i, path := range paths {
+		ants := antsPerPath[i]
+		if ants == 0 {
+			continue
+		}
+		length := int64(len(path) - 1)
+		turns = max(turns, saturatingAdd(length, ants-1))
+		// Somme des arrivées : ants * length + ants * (ants - 1) / 2 (on divise le facteur pair pour rester exact)
+		triangle := saturatingMul(ants, (ants-1)/2)
+		if ants%2 == 0 {
+			triangle = saturatingMul(ants/2, ants-1)
+		}
+		sumArrival = saturatingAdd(sumArrival, saturatingAdd(saturatingMul(ants, length), triangle))
+		totalMoves = saturatingAdd(totalMoves, saturatingMul(ants, length))
+	}
+	return turns, sumArrival, totalMoves
+}
+
+func saturatingAdd(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int64) int64 {
+	if a != 0 && b > math.MaxInt64/a {
+		return math.MaxInt64
+	}
+	return a * b
+}
+
+// Calcule les mêmes mesures pour des mouvements explicites, en repérant le tour d'arrivée de chaque fourmi.
+func measureMoves(moves [][]modules.Move, exit *modules.Room) (turns, sumArrival, totalMoves int64) {
+	for turn, line := range moves {
+		for _, move := range line {
+			totalMoves++
+			if move.Room == exit {
+				sumArrival += int64(turn + 1)
+				turns = int64(turn + 1)
+			}
+		}
+	}
+	return turns, sumArrival, totalMoves
+}
@@ -2,9 +2,13 @@
 package colony
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"lem-in/layout"
 	"lem-in/modules"
-	"sort"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -30,96 +34,207 @@ func PrintPath(path []*modules.Room) {
 }
 
 // Print les salles de la colonie en prenant en compte les coordonnées (mais sans les liens)
+// Si la colonie possède plusieurs étages, chaque étage est affiché séparément.
 func PrintColony(roomlist []*modules.Room) {
-	heigh, width := calculateSize(roomlist)
-	for line := 0; line <= heigh; line++ {
-		var strline string
-		for coloumn := 0; coloumn <= width; coloumn++ {
-			needPlacement := false
-			for _, room := range roomlist {
-				if room.Coordinates.Y == line && room.Coordinates.X == coloumn {
-					strline += string(room.Name[0])
-					needPlacement = true
-				}
+	grid := make(map[*modules.Room]modules.Point, len(roomlist))
+	for _, room := range roomlist {
+		grid[room] = room.Coordinates
+	}
+	printGrid(roomlist, grid)
+}
+
+// Print les salles de la colonie à la place donnée par une disposition (voir le package layout), pour les colonies
+// dont les coordonnées sont absentes, trop grandes ou superposées. Une unité de la disposition vaut 4 colonnes et 2 lignes.
+func PrintColonyLayout(roomlist []*modules.Room, positions layout.Positions) {
+	printGrid(roomlist, layout.Grid(positions, 4, 2))
+}
+
+// Print chaque étage de la colonie, chaque salle étant placée à la case donnée par grid.
+func printGrid(roomlist []*modules.Room, grid map[*modules.Room]modules.Point) {
+	heigh, width := calculateSize(grid)
+	floors := Floors(roomlist)
+	for i, floor := range floors {
+		if len(floors) > 1 {
+			if i > 0 {
+				fmt.Println("")
 			}
-			if !needPlacement {
-				strline += "."
+			fmt.Printf("Floor %d :\n", floor)
+		}
+		for line := 0; line <= heigh; line++ {
+			var strline string
+			for coloumn := 0; coloumn <= width; coloumn++ {
+				needPlacement := false
+				for _, room := range roomlist {
+					if grid[room].Z == floor && grid[room].Y == line && grid[room].X == coloumn {
+						strline += string(room.Name[0])
+						needPlacement = true
+					}
+				}
+				if !needPlacement {
+					strline += "."
+				}
 			}
+			fmt.Println(strline)
 		}
-		fmt.Println(strline)
 	}
 }
 
 // Calcule la ligne la plus basse et la ligne la plus à droite de la colonie.
-func calculateSize(roomlist []*modules.Room) (height, width int) {
+func calculateSize(grid map[*modules.Room]modules.Point) (height, width int) {
 	maxheigh := 0
 	maxwidth := 0
-	for _, room := range roomlist {
-		if room.Coordinates.Y > maxheigh {
-			maxheigh = room.Coordinates.Y
+	for _, point := range grid {
+		if point.Y > maxheigh {
+			maxheigh = point.Y
 		}
-		if room.Coordinates.X > maxwidth {
-			maxwidth = room.Coordinates.X
+		if point.X > maxwidth {
+			maxwidth = point.X
 		}
 	}
 	return maxheigh, maxwidth
 }
 
-// Print la résolution de l'algorithme.
-func PrintResolve(nbAnt int, paths [][]*modules.Room) {
-	// On trie les différents chemins utilisés par longueur
-	sort.Slice(paths, func(i, j int) bool {
-		return len(paths[i]) < len(paths[j])
-	})
-
-	// On utilise calculateTime pour savoir combien de fourmi va être envoyé dans chaque chemin.
-	_, antsPerPath := calculateTime(nbAnt, paths)
+// Écrit la colonie dans w au format des fichiers de files/ : le nombre de fourmis, les salles (l'entrée après ##start
+// en premier, la sortie après ##end en dernier), puis chaque tunnel une seule fois. L'étage n'est écrit que s'il n'est pas nul.
+// graph contient l'entrée en premier et la sortie en dernier.
+func WriteColony(w io.Writer, nbAnt int64, graph []*modules.Room) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, nbAnt)
+	index := make(map[*modules.Room]int, len(graph))
+	for i, room := range graph {
+		index[room] = i
+		if i == 0 {
+			fmt.Fprintln(writer, "##start")
+		} else if i == len(graph)-1 {
+			fmt.Fprintln(writer, "##end")
+		}
+		fmt.Fprintf(writer, "%s %d %d", room.Name, room.Coordinates.X, room.Coordinates.Y)
+		if room.Coordinates.Z != 0 {
+			fmt.Fprintf(writer, " %d", room.Coordinates.Z)
+		}
+		writer.WriteByte('\n')
+	}
+	for i, room := range graph {
+		for _, neighbour := range room.Neighbours {
+			if j, exists := index[neighbour]; exists && j > i {
+				fmt.Fprintf(writer, "%s-%s\n", room.Name, neighbour.Name)
+			}
+		}
+	}
+	return writer.Flush()
+}
 
-	// id de la fourmi
-	var antIDs []int
-	// id du chemin emprunter par la fourmi
-	var antPaths []int
-	var antPositions []int
-	antsSent := 0
-	antsFinished := 0
+// Print la résolution de l'algorithme.
+func PrintResolve(nbAnt int64, paths [][]*modules.Room) {
+	PrintSolution(NewSolution(nbAnt, paths, true, nil))
+}
 
-	// Garde en mémoire combien de fourmi a été envoyée dans chaque chemin.
-	pathCursor := make([]int, len(paths))
+// Print les mouvements des fourmis d'une solution, tour par tour.
+func PrintSolution(solution modules.Solution) {
+	WriteSolution(os.Stdout, solution)
+}
 
-	// Boucle qui tourne tant que toutes les fourmis n'ont pas atteint la fin.
-	for turn := 1; antsFinished < nbAnt; turn++ {
+// Écrit les mouvements des fourmis d'une solution dans w, une ligne par tour.
+func WriteSolution(w io.Writer, solution modules.Solution) error {
+	writer := bufio.NewWriter(w)
+	if solution.Moves != nil {
+		writeSchedule(writer, solution.Moves)
+	} else {
+		writeMoves(writer, solution.Paths, solution.AntsPerPath)
+	}
+	return writer.Flush()
+}
 
-		// On déplace les fourmis déjà présentes et on print leur position.
-		for i := 0; i < len(antIDs); i++ {
-			if antPositions[i] < len(paths[antPaths[i]])-1 {
-				// La fourmi avance d'un rang dans le chemin
-				antPositions[i]++
-				// On récupère le nom de la salle ou elle se trouve maintenant.
-				room := paths[antPaths[i]][antPositions[i]].Name
-				fmt.Printf("L%d-%s ", antIDs[i], room)
-				// On vérifie si elle a atteint la fin ce tour-ci
-				if antPositions[i] == len(paths[antPaths[i]])-1 {
-					antsFinished++
+// Renvoie les mouvements de chaque tour d'une solution, dans l'ordre où WriteSolution les écrit.
+// Les solutions décrites par leurs chemins sont développées fourmi par fourmi : à réserver aux affichages et exports.
+func SolutionMoves(solution modules.Solution) [][]modules.Move {
+	if solution.Moves != nil {
+		return solution.Moves
+	}
+	var moves [][]modules.Move
+	var id int64
+	// La fourmi de la vague w dans un chemin entre dans la salle i de ce chemin au tour w + i
+	for wave := int64(0); ; wave++ {
+		sent := false
+		for p, path := range solution.Paths {
+			if solution.AntsPerPath[p] <= wave {
+				continue
+			}
+			sent = true
+			id++
+			for i := 1; i < len(path); i++ {
+				turn := int(wave) + i - 1
+				for len(moves) <= turn {
+					moves = append(moves, nil)
 				}
+				moves[turn] = append(moves[turn], modules.Move{Ant: id, Room: path[i]})
 			}
 		}
+		if !sent {
+			return moves
+		}
+	}
+}
+
+// Écrit des mouvements explicites, une ligne par tour, au même format que writeMoves.
+func writeSchedule(writer *bufio.Writer, moves [][]modules.Move) {
+	var buffer []byte
+	for _, turn := range moves {
+		for _, move := range turn {
+			buffer = append(buffer[:0], 'L')
+			buffer = strconv.AppendInt(buffer, move.Ant, 10)
+			buffer = append(buffer, '-')
+			buffer = append(buffer, move.Room.Name...)
+			buffer = append(buffer, ' ')
+			writer.Write(buffer)
+		}
+		writer.WriteByte('\n')
+	}
+}
+
+// Écrit les mouvements de chaque tour sans garder les fourmis en mémoire.
+// Les fourmis partent par "vagues" : à chaque tour, chaque chemin qui doit encore recevoir des fourmis en reçoit une.
+// La fourmi de la vague w dans le chemin p se trouve donc, au tour t, dans la salle t - w de ce chemin.
+// Les identifiants sont attribués dans l'ordre de départ (vague par vague, puis chemin par chemin).
+func writeMoves(writer *bufio.Writer, paths [][]*modules.Room, antsPerPath []int64) {
+	// Calcule le dernier tour (arrivée de la dernière fourmi) et la longueur du plus long chemin utilisé
+	var lastTurn, longest, waves int64
+	for p, path := range paths {
+		if antsPerPath[p] == 0 {
+			continue
+		}
+		lastTurn = max(lastTurn, saturatingAdd(antsPerPath[p]-1, int64(len(path)-1)))
+		longest = max(longest, int64(len(path)))
+		waves = max(waves, antsPerPath[p])
+	}
 
-		// On prépare les nouvelles fourmis (mais sans les envoyés, elle sont positionnés sur le start! )
-		for path := range paths {
-			// Pour chaque chemin, on compare le nombre de fourmis à envoyer au nombre de fourmis déjà) envoyées.
-			if pathCursor[path] < antsPerPath[path] {
-				// Une fourmi de plus est placée et sa place est reservée dans le chemin qu'elle va emprunter.
-				antsSent++
-				pathCursor[path]++
-				antIDs = append(antIDs, antsSent)
-				antPaths = append(antPaths, path)
-				antPositions = append(antPositions, 0)
+	var buffer []byte
+	for turn := int64(1); turn <= lastTurn; turn++ {
+		// Les vagues plus anciennes ont déjà atteint la fin de tous les chemins
+		firstWave := max(0, turn-(longest-1))
+		// L'identifiant de la première fourmi de firstWave est le nombre de fourmis parties avant elle + 1
+		id := int64(1)
+		for p := range paths {
+			id += min(antsPerPath[p], firstWave)
+		}
+		for wave := firstWave; wave < turn && wave < waves; wave++ {
+			for p, path := range paths {
+				if antsPerPath[p] <= wave {
+					continue
+				}
+				position := turn - wave
+				if position <= int64(len(path)-1) {
+					buffer = append(buffer[:0], 'L')
+					buffer = strconv.AppendInt(buffer, id, 10)
+					buffer = append(buffer, '-')
+					buffer = append(buffer, path[position].Name...)
+					buffer = append(buffer, ' ')
+					writer.Write(buffer)
+				}
+				id++
 			}
 		}
 		// Saute la ligne au changement de tour
-		// Le premier tour ne faisant qu'envoyer les premières fourmis sans faire de print, on l'ignore pour éviter une double ligne vide
-		if turn > 1 {
-			fmt.Println("")
-		}
+		writer.WriteByte('\n')
 	}
 }
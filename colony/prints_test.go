@@ -0,0 +1,100 @@
+package colony
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"lem-in/modules"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteMovesPassesCheckMoves(t *testing.T) {
+	for _, file := range []string{"example00.txt", "example01.txt", "example03.txt", "example05.txt", "example09.txt"} {
+		graph, _ := loadMap(t, file)
+		for _, ants := range []int64{1, 2, 3, 7, 40, 1000} {
+			solution, err := BruteForce{}.Solve(context.Background(), graph, ants)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if solution.Moves != nil {
+				t.Fatalf("%s : the bruteforce solution is expected to be written by writeMoves", file)
+			}
+			checkSolution(t, file, graph, ants, solution)
+		}
+	}
+}
+
+// writeMoves ne garde pas les fourmis en mémoire : ses allocations ne dépendent pas du nombre de fourmis.
+func TestWriteMovesAllocations(t *testing.T) {
+	graph, _ := loadMap(t, "example01.txt")
+	allocations := func(ants int64) float64 {
+		solution := NewSolution(ants, greedyIndep(graph[0], graph[len(graph)-1]), false, nil)
+		writer := bufio.NewWriter(io.Discard)
+		return testing.AllocsPerRun(3, func() {
+			writeMoves(writer, solution.Paths, solution.AntsPerPath)
+		})
+	}
+	few, many := allocations(100), allocations(200_000)
+	if many > few+5 {
+		t.Errorf("%v allocations for 100 ants, %v for 200000 ants", few, many)
+	}
+}
+
+// Arrête l'écriture en paniquant une fois limit octets reçus.
+type stopWriter struct {
+	strings.Builder
+	limit int
+}
+
+type stopWriting struct{}
+
+func (w *stopWriter) Write(p []byte) (int, error) {
+	w.Builder.Write(p)
+	if w.Len() >= w.limit {
+		panic(stopWriting{})
+	}
+	return len(p), nil
+}
+
+// Début des mouvements écrits par writeMoves, interrompu après quelques kilo-octets.
+func firstMoves(t *testing.T, paths [][]*modules.Room, antsPerPath []int64) string {
+	t.Helper()
+	output := &stopWriter{limit: 4096}
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(stopWriting); !ok {
+					panic(r)
+				}
+			}
+		}()
+		writer := bufio.NewWriterSize(output, 512)
+		writeMoves(writer, paths, antsPerPath)
+		writer.Flush()
+	}()
+	return output.String()
+}
+
+// Avec un nombre de fourmis proche de la limite des int64, les premiers tours s'écrivent aussitôt (sans une entrée
+// par fourmi) et sont ceux d'une solution plus petite qui utilise les mêmes chemins.
+func TestWriteMovesLargeAnts(t *testing.T) {
+	graph, _ := loadMap(t, "example01.txt")
+	paths := greedyIndep(graph[0], graph[len(graph)-1])
+	for _, paths := range [][][]*modules.Room{paths, paths[:1]} {
+		large := NewSolution(math.MaxInt64-1, paths, false, nil)
+		small := NewSolution(10_000, paths, false, nil)
+		compareFirstTurns(t, firstMoves(t, large.Paths, large.AntsPerPath), firstMoves(t, small.Paths, small.AntsPerPath))
+	}
+}
+
+func compareFirstTurns(t *testing.T, got, want string) {
+	t.Helper()
+	// Les dernières lignes peuvent être coupées : on compare les tours complets
+	got, want = got[:strings.LastIndex(got, "\n")], want[:strings.LastIndex(want, "\n")]
+	lines := min(strings.Count(got, "\n"), strings.Count(want, "\n"))
+	if lines < 10 || strings.Join(strings.SplitN(got, "\n", lines+1)[:lines], "\n") != strings.Join(strings.SplitN(want, "\n", lines+1)[:lines], "\n") {
+		t.Fatalf("first turns differ :\n%s\n----\n%s", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package colony
 
 import (
 	"lem-in/modules"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -25,50 +26,29 @@ func containsRoom(rooms []*modules.Room, target *modules.Room) bool {
 	return false
 }
 
+// Créer une salle à partir de sa définition "Nom X Y" ou "Nom X Y Z" (l'étage Z vaut 0 s'il est absent).
+func creatRoom(line string) *modules.Room {
+	parts := strings.Fields(line)
+	room := modules.Room{Name: parts[0]}
+	room.Coordinates.X, _ = strconv.Atoi(parts[1])
+	room.Coordinates.Y, _ = strconv.Atoi(parts[2])
+	if len(parts) == 4 {
+		room.Coordinates.Z, _ = strconv.Atoi(parts[3])
+	}
+	return &room
+}
+
 // Créer l'ensemble des salles, sans les liens, à partir des datas.
 func CreatRooms(datas modules.Datas) []*modules.Room {
 	var rooms []*modules.Room
-	var tempX int
-	var tempY int
-	// On créé la salle d'entrée
-	tempX, _ = strconv.Atoi(strings.Fields(datas.Start)[1])
-	tempY, _ = strconv.Atoi(strings.Fields(datas.Start)[2])
-	entry := modules.Room{
-		Name: strings.Fields(datas.Start)[0],
-		Coordinates: modules.Point{
-			X: tempX,
-			Y: tempY,
-		},
-	}
-
-	// On créé la salle de sortie
-	tempX, _ = strconv.Atoi(strings.Fields(datas.End)[1])
-	tempY, _ = strconv.Atoi(strings.Fields(datas.End)[2])
-	exit := modules.Room{
-		Name: strings.Fields(datas.End)[0],
-		Coordinates: modules.Point{
-			X: tempX,
-			Y: tempY,
-		},
-	}
 	// On place l'entrée au début de la slice qu'on va retourner
-	rooms = append(rooms, &entry)
+	rooms = append(rooms, creatRoom(datas.Start))
 	// On créé et ajoute toutes les salles intermédiaires
 	for _, room := range datas.Rooms {
-		tempName := strings.Fields(room)[0]
-		tempX, _ = strconv.Atoi(strings.Fields(room)[1])
-		tempY, _ = strconv.Atoi(strings.Fields(room)[2])
-		temp := modules.Room{
-			Name: tempName,
-			Coordinates: modules.Point{
-				X: tempX,
-				Y: tempY,
-			},
-		}
-		rooms = append(rooms, &temp)
+		rooms = append(rooms, creatRoom(room))
 	}
 	// On ajoute la salle de sortie à la fin de la liste
-	rooms = append(rooms, &exit)
+	rooms = append(rooms, creatRoom(datas.End))
 	return rooms
 }
 
@@ -100,3 +80,15 @@ func GetRoomByName(name string, rooms []*modules.Room) *modules.Room {
 	}
 	return nil
 }
+
+// Renvoie la liste triée des étages (coordonnée Z) occupés par au moins une salle.
+func Floors(rooms []*modules.Room) []int {
+	var floors []int
+	for _, r := range rooms {
+		if !slices.Contains(floors, r.Coordinates.Z) {
+			floors = append(floors, r.Coordinates.Z)
+		}
+	}
+	slices.Sort(floors)
+	return floors
+}
@@ -0,0 +1,85 @@
+// Package colony provides the solver interface and the registry of available strategies.
+package colony
+
+import (
+	"context"
+	"errors"
+	"lem-in/modules"
+	"sort"
+	"strings"
+)
+
+// Erreur renvoyée par les solveurs lorsque l'entrée et la sortie ne sont pas reliées
+var ErrNoPath = errors.New("No path between start and end")
+
+// Solver est une stratégie de résolution : elle reçoit la colonie (entrée en premier, sortie en dernier) et le nombre de fourmis.
+// Si le contexte est annulé, un solveur renvoie la meilleure solution trouvée avec Optimal à false.
+type Solver interface {
+	Solve(ctx context.Context, graph []*modules.Room, ants int64) (modules.Solution, error)
+}
+
+// Les solveurs sont enregistrés sous forme de constructeurs pour leur transmettre les réglages communs.
+var solvers = make(map[string]func(Options) Solver)
+
+// Enregistre un solveur sous un nom, pour pouvoir le choisir au lancement du programme.
+func RegisterSolver(name string, factory func(Options) Solver) {
+	if _, exists := solvers[name]; exists {
+		panic("colony: solver " + name + " registered twice")
+	}
+	solvers[name] = factory
+}
+
+// Créer le solveur enregistré sous ce nom avec les réglages donnés.
+func NewSolver(name string, opts Options) (Solver, error) {
+	factory, exists := solvers[name]
+	if !exists {
+		return nil, errors.New("Unknown solver : " + name + " (available : " + strings.Join(SolverNames(), ", ") + ")")
+	}
+	return factory(opts), nil
+}
+
+// Renvoie les noms de tous les solveurs enregistrés, triés par ordre alphabétique.
+func SolverNames() []string {
+	var names []string
+	for name := range solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BruteForce évalue toutes les combinaisons de chemins indépendants (voir Resolve).
+type BruteForce struct {
+	Options Options
+}
+
+func (b BruteForce) Solve(ctx context.Context, graph []*modules.Room, ants int64) (modules.Solution, error) {
+	bestset, complete := Resolve(ctx, ants, graph, b.Options)
+	if bestset == nil {
+		return modules.Solution{}, ErrNoPath
+	}
+	return NewSolution(ants, bestset, complete, b.Options.Objective), nil
+}
+
+// Construit la solution correspondant à l'envoi des fourmis dans une combinaison de chemins, réparties selon l'objectif.
+func NewSolution(ants int64, paths [][]*modules.Room, optimal bool, objective Objective) modules.Solution {
+	// On trie les chemins par longueur (sur une copie, pour ne pas modifier la combinaison reçue)
+	paths = append([][]*modules.Room{}, paths...)
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) < len(paths[j])
+	})
+	antsPerPath := objective.assignAnts(ants, paths)
+	turns, sumArrival, totalMoves := measure(paths, antsPerPath)
+	return modules.Solution{
+		Paths:       paths,
+		AntsPerPath: antsPerPath,
+		Turns:       turns,
+		SumArrival:  sumArrival,
+		TotalMoves:  totalMoves,
+		Optimal:     optimal,
+	}
+}
+
+func init() {
+	RegisterSolver("bruteforce", func(opts Options) Solver { return BruteForce{Options: opts} })
+}
@@ -0,0 +1,101 @@
+// Package colony provides the evolution of the best path combination with the number of ants.
+package colony
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lem-in/modules"
+	"sort"
+)
+
+// Point de la courbe "nombre de tours en fonction du nombre de fourmis".
+type SweepPoint struct {
+	Ants       int64
+	Turns      int64
+	Paths      [][]*modules.Room // Meilleure combinaison de chemins, triés par longueur
+	Breakpoint bool              // La combinaison du point précédent n'est plus optimale à partir de ce nombre de fourmis
+}
+
+// Calcule la meilleure combinaison de IndepPaths pour chaque nombre de fourmis de from à to (par pas de step). Seuls
+// les chemins qui reçoivent des fourmis font partie de la combinaison. Tant qu'elle reste optimale, la combinaison du
+// point précédent est conservée : Breakpoint ne signale que les nombres de fourmis à partir desquels une autre
+// combinaison devient strictement meilleure.
+func Sweep(ctx context.Context, graph []*modules.Room, from, to, step int64, opts Options) ([]SweepPoint, error) {
+	if from < 1 || to < from || step < 1 {
+		return nil, errors.New("Bad sweep range : from " + fmt.Sprint(from) + " to " + fmt.Sprint(to) + " by " + fmt.Sprint(step))
+	}
+	// Les mesures d'une combinaison ne dépendent que des longueurs de ses chemins :
+	// on ne garde que la première combinaison trouvée pour chaque liste de longueurs
+	var candidates [][][]*modules.Room
+	seen := make(map[string]bool)
+	paths := OptimizePaths(FindAllPaths(ctx, graph[0], graph[len(graph)-1], nil))
+	exploreIndep(ctx, paths, func(set [][]*modules.Room) {
+		if key := lengthsKey(set); !seen[key] {
+			seen[key] = true
+			candidates = append(candidates, set)
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoPath
+	}
+
+	var points []SweepPoint
+	var current [][]*modules.Room
+	for ants := from; ; ants += step {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		bestSolution := NewSolution(ants, candidates[0], true, opts.Objective)
+		best := usedPaths(bestSolution)
+		for _, set := range candidates[1:] {
+			// À égalité, la combinaison avec le moins de chemins utilisés est préférée, pour que chaque changement
+			// corresponde à un chemin utile
+			solution := NewSolution(ants, set, true, opts.Objective)
+			used := usedPaths(solution)
+			if opts.Objective.Less(solution, bestSolution) || (!opts.Objective.Less(bestSolution, solution) && len(used) < len(best)) {
+				best, bestSolution = used, solution
+			}
+		}
+		point := SweepPoint{Ants: ants}
+		if current != nil {
+			if previous := NewSolution(ants, current, true, opts.Objective); opts.Objective.Less(bestSolution, previous) {
+				point.Breakpoint = true
+			} else {
+				best, bestSolution = usedPaths(previous), previous
+			}
+		}
+		current = best
+		point.Turns, point.Paths = bestSolution.Turns, best
+		points = append(points, point)
+		// On s'arrête avant de dépasser to (ou la valeur maximale d'un int64)
+		if ants > to-step {
+			break
+		}
+	}
+	return points, nil
+}
+
+// Renvoie les chemins d'une solution qui reçoivent au moins une fourmi, dans leur ordre (par longueur).
+func usedPaths(solution modules.Solution) [][]*modules.Room {
+	var used [][]*modules.Room
+	for i, path := range solution.Paths {
+		if solution.AntsPerPath[i] > 0 {
+			used = append(used, path)
+		}
+	}
+	return used
+}
+
+// Clé identifiant la liste triée des longueurs des chemins d'une combinaison.
+func lengthsKey(set [][]*modules.Room) string {
+	lengths := make([]int, len(set))
+	for i, path := range set {
+		lengths[i] = len(path)
+	}
+	sort.Ints(lengths)
+	return fmt.Sprint(lengths)
+}
@@ -0,0 +1,29 @@
+package colony
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSweepBreakpoints(t *testing.T) {
+	graph, _ := parseMap(t, twoPathsMap)
+	points, err := Sweep(context.Background(), graph, 1, 6, 1, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Jusqu'à 2 fourmis, le chemin court suffit ; le chemin long devient utile à partir de 3 fourmis
+	want := []struct {
+		turns      int64
+		paths      int
+		breakpoint bool
+	}{{2, 1, false}, {3, 1, false}, {3, 2, true}, {4, 2, false}, {4, 2, false}, {5, 2, false}}
+	if len(points) != len(want) {
+		t.Fatalf("%d points, want %d", len(points), len(want))
+	}
+	for i, point := range points {
+		if point.Ants != int64(i+1) || point.Turns != want[i].turns || len(point.Paths) != want[i].paths || point.Breakpoint != want[i].breakpoint {
+			t.Errorf("point %d : %d ants, %d turns, %d paths, breakpoint %v ; want %d turns, %d paths, breakpoint %v", i,
+				point.Ants, point.Turns, len(point.Paths), point.Breakpoint, want[i].turns, want[i].paths, want[i].breakpoint)
+		}
+	}
+}
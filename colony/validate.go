@@ -0,0 +1,102 @@
+// Package colony provides the verification of ant movements against the rules of the colony.
+package colony
+
+import (
+	"fmt"
+	"lem-in/modules"
+	"strconv"
+	"strings"
+)
+
+// Vérifie qu'une liste de mouvements (une ligne "L<id>-<salle> ..." par tour) respecte les règles de la colonie :
+// - chaque fourmi bouge au plus une fois par tour, en empruntant un tunnel qui part de sa salle actuelle ;
+// - un tunnel n'est emprunté que par une seule fourmi par tour ;
+// - à la fin d'un tour, chaque salle (hors entrée et sortie) contient au plus une fourmi ;
+// - toutes les fourmis ont atteint la sortie après le dernier tour.
+// Renvoie le nombre de tours utilisés.
+func CheckMoves(ants int64, graph []*modules.Room, lines []string) (int64, error) {
+	entry, exit := graph[0], graph[len(graph)-1]
+	// Seules les fourmis déjà parties sont enregistrées, les autres attendent dans l'entrée
+	positions := make(map[int64]*modules.Room)
+	occupants := make(map[*modules.Room]int64)
+	arrived := int64(0)
+	byName := make(map[string]*modules.Room, len(graph))
+	for _, room := range graph {
+		byName[room.Name] = room
+	}
+
+	var turn int64
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		turn++
+		moved := make(map[int64]bool)
+		usedTunnels := make(map[[2]*modules.Room]bool)
+		var entered []*modules.Room
+		for _, move := range strings.Fields(line) {
+			id, dest, err := parseMove(move, ants, byName)
+			if err != nil {
+				return turn, fmt.Errorf("Turn %d : %w", turn, err)
+			}
+			if moved[id] {
+				return turn, fmt.Errorf("Turn %d : ant %d moves twice", turn, id)
+			}
+			moved[id] = true
+
+			from, started := positions[id]
+			if !started {
+				from = entry
+			}
+			if from == exit {
+				return turn, fmt.Errorf("Turn %d : ant %d moves after reaching the end", turn, id)
+			}
+			if !containsRoom(from.Neighbours, dest) {
+				return turn, fmt.Errorf("Turn %d : no tunnel between %s and %s for ant %d", turn, from.Name, dest.Name, id)
+			}
+			tunnel := [2]*modules.Room{from, dest}
+			if from.Name > dest.Name {
+				tunnel = [2]*modules.Room{dest, from}
+			}
+			if usedTunnels[tunnel] {
+				return turn, fmt.Errorf("Turn %d : tunnel %s-%s used twice", turn, from.Name, dest.Name)
+			}
+			usedTunnels[tunnel] = true
+
+			occupants[from]--
+			occupants[dest]++
+			positions[id] = dest
+			entered = append(entered, dest)
+			if dest == exit {
+				arrived++
+			}
+		}
+		// Les salles libérées pendant le tour peuvent être occupées par une autre fourmi dans ce même tour
+		for _, room := range entered {
+			if room != entry && room != exit && occupants[room] > 1 {
+				return turn, fmt.Errorf("Turn %d : %d ants in room %s", turn, occupants[room], room.Name)
+			}
+		}
+	}
+	if arrived != ants {
+		return turn, fmt.Errorf("Only %d of %d ants reached the end", arrived, ants)
+	}
+	return turn, nil
+}
+
+// Découpe un mouvement "L<id>-<salle>" et vérifie que la fourmi et la salle existent
+func parseMove(move string, ants int64, byName map[string]*modules.Room) (int64, *modules.Room, error) {
+	idStr, roomName, found := strings.Cut(strings.TrimPrefix(move, "L"), "-")
+	if !strings.HasPrefix(move, "L") || !found {
+		return 0, nil, fmt.Errorf("bad format for move %s", move)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id < 1 || id > ants {
+		return 0, nil, fmt.Errorf("unknown ant in move %s", move)
+	}
+	dest, exists := byName[roomName]
+	if !exists {
+		return 0, nil, fmt.Errorf("unknown room in move %s", move)
+	}
+	return id, dest, nil
+}
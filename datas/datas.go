@@ -2,35 +2,19 @@
 package datas
 
 import (
-	"bufio"
 	"errors"
 	"lem-in/modules"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 )
 
-// Stock les instructions lignes par lignes
+// Stock les instructions lignes par lignes, en développant les directives ##include
 func GetDatas(filename string) []string {
-	file, err := os.Open("files/" + filename)
+	results, err := ReadFile("files/" + filename)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
-
-	var results []string
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		results = append(results, line)
-	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatal(err)
-	}
-
 	return results
 }
 
@@ -52,7 +36,8 @@ func SaveDatas(filecontent []string) modules.Datas {
 		}
 		// La première ligne est forcément le nombre de fourmis. On vérifira plus tard que le nombre est logique.
 		if i == 0 {
-			datas.NbAnts, err = strconv.Atoi(line)
+			// Le nombre est lu sur 64 bits, un nombre trop grand est refusé plutôt que tronqué
+			datas.NbAnts, err = strconv.ParseInt(line, 10, 64)
 			if err != nil {
 				datas.Errors = append(datas.Errors, errors.New("Bad format for number of ants"))
 				return datas
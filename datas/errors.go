@@ -3,6 +3,7 @@ package datas
 import (
 	"errors"
 	"lem-in/modules"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
@@ -13,6 +14,10 @@ func CheckErrors(datas *modules.Datas) {
 	if datas.NbAnts <= 0 {
 		datas.Errors = append(datas.Errors, errors.New("Bad format for number of ant"))
 	}
+	// Le nombre de tours vaut au plus le nombre de fourmis + la longueur d'un chemin, il doit rester calculable sur 64 bits
+	if datas.NbAnts > math.MaxInt64-int64(len(datas.Rooms)+2) {
+		datas.Errors = append(datas.Errors, errors.New("Too many ants"))
+	}
 	checkExtrimities(datas)
 	if len(datas.Rooms) != 0 {
 		checkRooms(datas)
@@ -35,13 +40,11 @@ func checkExtrimities(datas *modules.Datas) {
 func checkRooms(datas *modules.Datas) {
 	for _, roomstr := range datas.Rooms {
 		roomtab := strings.Fields(roomstr)
-		if len(roomtab) != 3 {
+		if len(roomtab) != 3 && len(roomtab) != 4 {
 			datas.Errors = append(datas.Errors, errors.New("Bad format for the following room : "+roomstr))
 			continue
 		}
-		_, err1 := strconv.Atoi(roomtab[1])
-		_, err2 := strconv.Atoi(roomtab[2])
-		if err1 != nil || err2 != nil {
+		if !areCoordinates(roomtab[1:]) {
 			datas.Errors = append(datas.Errors, errors.New("Bad format for the following room : "+roomstr))
 			continue
 		}
@@ -82,20 +85,28 @@ func checkLinks(datas *modules.Datas) {
 	}
 }
 
-// Vérifie que le string est bien au format "Nom X Y"
+// Vérifie que le string est bien au format "Nom X Y" ou "Nom X Y Z" (Z étant l'étage de la salle)
 func checkRoomFormat(line string) string {
 	parts := strings.Fields(line)
-	if len(parts) != 3 {
+	if len(parts) != 3 && len(parts) != 4 {
 		return "Bad format : comment without # : " + line
 	}
-	_, err1 := strconv.Atoi(parts[1])
-	_, err2 := strconv.Atoi(parts[2])
-	if err1 != nil || err2 != nil {
+	if !areCoordinates(parts[1:]) {
 		return "Bad format for room : " + line
 	}
 	return ""
 }
 
+// Vérifie que toutes les coordonnées d'une salle sont des entiers
+func areCoordinates(coordinates []string) bool {
+	for _, coordinate := range coordinates {
+		if _, err := strconv.Atoi(coordinate); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 // Vérifie qu'aucune salle n'est définie deux fois.
 func checkDuplicates(datas *modules.Datas) {
 	var duplicatesIndex []int
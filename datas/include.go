@@ -0,0 +1,168 @@
+package datas
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Directive permettant d'inclure les salles et liens d'un autre fichier : "##include chemin [préfixe]"
+const includeDirective = "##include"
+
+// Lit un fichier de colonie (chemin quelconque) et développe récursivement toutes ses directives ##include.
+// Les salles incluses sont placées à l'endroit de la directive, les liens inclus rejoignent la partie des liens.
+func ReadFile(path string) ([]string, error) {
+	rooms, links, err := expandFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(rooms, links...), nil
+}
+
+// Renvoie séparément la partie "salles" (nombre de fourmis, commandes, salles) et la partie "liens" d'un fichier.
+// stack contient les fichiers en cours de développement pour détecter les inclusions circulaires.
+func expandFile(path string, stack []string) ([]string, []string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, included := range stack {
+		if included == absPath {
+			chain := append(append([]string{}, stack[i:]...), absPath)
+			return nil, nil, errors.New("Include cycle : " + strings.Join(chain, " -> "))
+		}
+	}
+	stack = append(stack, absPath)
+
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rooms []string
+	var links []string
+	// Fichier inclus par chaque préfixe : deux inclusions du même fichier avec le même préfixe créeraient deux fois
+	// les mêmes salles
+	prefixes := make(map[string]string)
+	// Comme dans SaveDatas, le premier tiret rencontré marque le début des liens.
+	isRoom := true
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == includeDirective {
+			target, prefix, err := parseInclude(path, fields)
+			if err != nil {
+				return nil, nil, err
+			}
+			if other, used := prefixes[prefix]; used {
+				return nil, nil, errors.New("Include prefix used twice : " + prefix + " (" + other + " and " + fields[1] +
+					"), give each include its own prefix")
+			}
+			prefixes[prefix] = fields[1]
+			subRooms, subLinks, err := expandFile(target, stack)
+			if err != nil {
+				return nil, nil, err
+			}
+			subRooms, subLinks = prefixNames(subRooms, subLinks, prefix)
+			rooms = append(rooms, subRooms...)
+			links = append(links, subLinks...)
+			continue
+		}
+		// Un fichier inclus n'apporte que des salles et des liens : l'entrée, la sortie et le nombre de fourmis
+		// appartiennent au fichier principal
+		if len(stack) > 1 {
+			if line == "##start" || line == "##end" {
+				return nil, nil, errors.New(line + " not allowed in an included file : " + path)
+			}
+			if _, err := strconv.ParseInt(line, 10, 64); err == nil {
+				return nil, nil, errors.New("Number of ants not allowed in an included file : " + path)
+			}
+		}
+		if line != "" && line[0] != '#' && strings.Contains(line, "-") {
+			isRoom = false
+		}
+		if isRoom {
+			rooms = append(rooms, line)
+		} else {
+			links = append(links, line)
+		}
+	}
+	return rooms, links, nil
+}
+
+// Récupère le chemin du fichier à inclure (relatif au fichier qui l'inclut) et le préfixe à appliquer à ses salles.
+// Sans préfixe explicite, on utilise le nom du fichier sans son extension.
+func parseInclude(from string, fields []string) (string, string, error) {
+	if len(fields) != 2 && len(fields) != 3 {
+		return "", "", errors.New("Bad format for include : " + strings.Join(fields, " "))
+	}
+	target := fields[1]
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(from), target)
+	}
+	prefix := strings.TrimSuffix(filepath.Base(target), filepath.Ext(target))
+	if len(fields) == 3 {
+		prefix = fields[2]
+	}
+	// Un tiret dans le préfixe rendrait les liens impossibles à découper
+	if prefix == "" || strings.Contains(prefix, "-") || prefix[0] == '#' || prefix[0] == 'L' {
+		return "", "", errors.New("Bad prefix for include : " + strings.Join(fields, " "))
+	}
+	return target, prefix, nil
+}
+
+// Renomme "préfixe_nom" toutes les salles définies dans un fichier inclus, ainsi que leurs occurrences dans les liens.
+// Les noms qui ne sont pas définis dans le fichier inclus (ex : une salle du fichier parent) sont laissés tels quels.
+func prefixNames(rooms, links []string, prefix string) ([]string, []string) {
+	renamed := make(map[string]string)
+	var newRooms []string
+	for _, line := range rooms {
+		if line == "" || line[0] == '#' || checkRoomFormat(line) != "" {
+			newRooms = append(newRooms, line)
+			continue
+		}
+		parts := strings.Fields(line)
+		renamed[parts[0]] = prefix + "_" + parts[0]
+		parts[0] = renamed[parts[0]]
+		newRooms = append(newRooms, strings.Join(parts, " "))
+	}
+
+	var newLinks []string
+	for _, line := range links {
+		left, right, found := strings.Cut(line, "-")
+		if line == "" || line[0] == '#' || !found {
+			newLinks = append(newLinks, line)
+			continue
+		}
+		if name, ok := renamed[left]; ok {
+			left = name
+		}
+		if name, ok := renamed[right]; ok {
+			right = name
+		}
+		newLinks = append(newLinks, fmt.Sprintf("%s-%s", left, right))
+	}
+	return newRooms, newLinks
+}
+
+// Lit un fichier ligne par ligne.
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var results []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		results = append(results, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
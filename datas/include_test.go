@@ -0,0 +1,120 @@
+package datas
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadFileIncludes(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string // Fichiers créés dans un dossier temporaire, le premier lu étant main.txt
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "explicit prefix, parent rooms untouched in links",
+			files: map[string]string{
+				"main.txt": "2\n##start\ns 0 0\n##end\ne 9 0\n##include wing.txt w\ns-e",
+				"wing.txt": "a 1 1\nb 2 1\ns-a\na-b\nb-e",
+			},
+			want: []string{"2", "##start", "s 0 0", "##end", "e 9 0", "w_a 1 1", "w_b 2 1", "s-w_a", "w_a-w_b", "w_b-e", "s-e"},
+		},
+		{
+			name: "default prefix from the file name",
+			files: map[string]string{
+				"main.txt":       "1\n##start\ns 0 0\n##end\ne 9 0\n##include wings/hall.txt\ns-e",
+				"wings/hall.txt": "x 1 1\ns-x",
+			},
+			want: []string{"1", "##start", "s 0 0", "##end", "e 9 0", "hall_x 1 1", "s-hall_x", "s-e"},
+		},
+		{
+			name: "same file twice with different prefixes",
+			files: map[string]string{
+				"main.txt": "1\n##start\ns 0 0\n##end\ne 9 0\n##include c.txt n\n##include c.txt s\ns-e",
+				"c.txt":    "a 1 1\ns-a",
+			},
+			want: []string{"1", "##start", "s 0 0", "##end", "e 9 0", "n_a 1 1", "s_a 1 1", "s-n_a", "s-s_a", "s-e"},
+		},
+		{
+			name: "nested includes relative to the including file",
+			files: map[string]string{
+				"main.txt":    "1\n##start\ns 0 0\n##end\ne 9 0\n##include a/outer.txt o\ns-e",
+				"a/outer.txt": "m 1 1\n##include ../b/inner.txt i\nm-i_n",
+				"b/inner.txt": "n 2 2\nn-e",
+			},
+			want: []string{"1", "##start", "s 0 0", "##end", "e 9 0", "o_m 1 1", "o_i_n 2 2", "o_i_n-e", "o_m-o_i_n", "s-e"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.txt": "1\n##start\ns 0 0\n##end\ne 9 0\n##include a.txt",
+				"a.txt":    "##include b.txt",
+				"b.txt":    "##include a.txt",
+			},
+			wantErr: "Include cycle",
+		},
+		{
+			name: "same default prefix twice",
+			files: map[string]string{
+				"main.txt": "1\n##start\ns 0 0\n##end\ne 9 0\n##include c.txt\n##include c.txt",
+				"c.txt":    "a 1 1",
+			},
+			wantErr: "Include prefix used twice : c",
+		},
+		{
+			name: "start in an included file",
+			files: map[string]string{
+				"main.txt": "1\n##start\ns 0 0\n##end\ne 9 0\n##include c.txt",
+				"c.txt":    "##start\na 1 1",
+			},
+			wantErr: "##start not allowed in an included file",
+		},
+		{
+			name: "ants in an included file",
+			files: map[string]string{
+				"main.txt": "1\n##start\ns 0 0\n##end\ne 9 0\n##include c.txt",
+				"c.txt":    "5\na 1 1",
+			},
+			wantErr: "Number of ants not allowed in an included file",
+		},
+		{
+			name: "bad prefix",
+			files: map[string]string{
+				"main.txt": "1\n##start\ns 0 0\n##end\ne 9 0\n##include c.txt a-b",
+				"c.txt":    "a 1 1",
+			},
+			wantErr: "Bad prefix for include",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			lines, err := ReadFile(filepath.Join(dir, "main.txt"))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(lines, test.want) {
+				t.Fatalf("got %q\nwant %q", lines, test.want)
+			}
+		})
+	}
+}
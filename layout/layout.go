@@ -0,0 +1,221 @@
+// Package layout computes positions for the rooms of a colony whose coordinates are missing or overlapping.
+package layout
+
+import (
+	"errors"
+	"lem-in/modules"
+	"math"
+	"sort"
+)
+
+// Position d'une salle calculée par une disposition. L'unité est la longueur idéale d'un tunnel.
+type Position struct {
+	X, Y float64
+}
+
+// Positions associe une position à chaque salle.
+type Positions map[*modules.Room]Position
+
+// Noms des dispositions disponibles
+const (
+	AutoLayout        = "auto"        // Les coordonnées si aucune salle n'en chevauche une autre, Layered sinon
+	CoordinatesLayout = "coordinates" // Les coordonnées de la carte
+	LayeredLayout     = "layered"     // Colonnes selon la distance à l'entrée
+	ForceLayout       = "force"       // Disposition par forces (ressorts et répulsion)
+)
+
+// Renvoie les noms des dispositions, dans l'ordre où le visualiseur les fait défiler.
+func Names() []string {
+	return []string{AutoLayout, CoordinatesLayout, LayeredLayout, ForceLayout}
+}
+
+// Calcule la disposition demandée. graph contient l'entrée en premier et la sortie en dernier.
+func Compute(name string, graph []*modules.Room) (Positions, error) {
+	switch name {
+	case AutoLayout:
+		if Overlapping(graph) {
+			return Layered(graph), nil
+		}
+		return FromCoordinates(graph), nil
+	case CoordinatesLayout:
+		return FromCoordinates(graph), nil
+	case LayeredLayout:
+		return Layered(graph), nil
+	case ForceLayout:
+		return ForceDirected(graph), nil
+	}
+	return nil, errors.New("Unknown layout : " + name + " (available : auto, coordinates, layered, force)")
+}
+
+// Vérifie si deux salles d'un même étage ont les mêmes coordonnées.
+func Overlapping(graph []*modules.Room) bool {
+	seen := make(map[modules.Point]bool, len(graph))
+	for _, room := range graph {
+		if seen[room.Coordinates] {
+			return true
+		}
+		seen[room.Coordinates] = true
+	}
+	return false
+}
+
+// Reprend les coordonnées X et Y de la carte.
+func FromCoordinates(graph []*modules.Room) Positions {
+	positions := make(Positions, len(graph))
+	for _, room := range graph {
+		positions[room] = Position{X: float64(room.Coordinates.X), Y: float64(room.Coordinates.Y)}
+	}
+	return positions
+}
+
+// Place les salles en colonnes selon leur distance (en tunnels) à l'entrée : l'entrée dans la première colonne,
+// la sortie seule dans la dernière. Les salles inaccessibles forment l'avant-dernière colonne.
+// Dans chaque colonne, les salles sont triées par la position moyenne de leurs voisins de la colonne précédente
+// (méthode du barycentre), ce qui limite les croisements de tunnels.
+func Layered(graph []*modules.Room) Positions {
+	entry, exit := graph[0], graph[len(graph)-1]
+	distance := distances(graph, entry)
+	deepest := 0
+	for room, d := range distance {
+		if room != exit {
+			deepest = max(deepest, d)
+		}
+	}
+	layers := make([][]*modules.Room, deepest+3)
+	for _, room := range graph {
+		d, reachable := distance[room]
+		switch {
+		case room == exit:
+			d = len(layers) - 1
+		case !reachable:
+			d = len(layers) - 2
+		}
+		layers[d] = append(layers[d], room)
+	}
+
+	positions := make(Positions, len(graph))
+	for column, layer := range layers {
+		if column > 0 {
+			sort.SliceStable(layer, func(i, j int) bool {
+				return barycenter(layer[i], positions) < barycenter(layer[j], positions)
+			})
+		}
+		for row, room := range layer {
+			positions[room] = Position{X: float64(column), Y: float64(row) - float64(len(layer)-1)/2}
+		}
+	}
+	return positions
+}
+
+// Distance en tunnels de chaque salle accessible depuis from (parcours en largeur).
+func distances(graph []*modules.Room, from *modules.Room) map[*modules.Room]int {
+	distance := map[*modules.Room]int{from: 0}
+	queue := []*modules.Room{from}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		for _, neighbour := range room.Neighbours {
+			if _, seen := distance[neighbour]; !seen {
+				distance[neighbour] = distance[room] + 1
+				queue = append(queue, neighbour)
+			}
+		}
+	}
+	return distance
+}
+
+// Ordonnée moyenne des voisins déjà placés d'une salle (0 si aucun ne l'est).
+func barycenter(room *modules.Room, positions Positions) float64 {
+	var sum float64
+	var count int
+	for _, neighbour := range room.Neighbours {
+		if position, placed := positions[neighbour]; placed {
+			sum += position.Y
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// Nombre d'itérations de ForceDirected
+const forceIterations = 300
+
+// Disposition par forces (Fruchterman-Reingold) : les tunnels attirent les salles qu'ils relient et toutes les salles
+// se repoussent. On part de la disposition Layered, sans hasard, donc le résultat est toujours le même.
+// L'entrée et la sortie restent fixes, et les autres salles restent entre les deux.
+func ForceDirected(graph []*modules.Room) Positions {
+	positions := Layered(graph)
+	entry, exit := graph[0], graph[len(graph)-1]
+	// Un léger décalage propre à chaque salle sépare les salles qui se repoussent exactement dans l'axe
+	for i, room := range graph {
+		if room == entry || room == exit {
+			continue
+		}
+		p := positions[room]
+		positions[room] = Position{X: p.X + 0.01*math.Sin(float64(i)), Y: p.Y + 0.01*math.Cos(float64(i))}
+	}
+
+	const ideal = 1.0 // Longueur idéale d'un tunnel
+	left, right := positions[entry].X+ideal/2, positions[exit].X-ideal/2
+	temperature := 0.5
+	for range forceIterations {
+		moves := make(map[*modules.Room]Position, len(graph))
+		for i, a := range graph {
+			for _, b := range graph[i+1:] {
+				dx, dy, length := delta(positions[a], positions[b])
+				force := ideal * ideal / length
+				moves[a] = Position{moves[a].X + dx/length*force, moves[a].Y + dy/length*force}
+				moves[b] = Position{moves[b].X - dx/length*force, moves[b].Y - dy/length*force}
+			}
+		}
+		for _, a := range graph {
+			for _, b := range a.Neighbours {
+				// Chaque tunnel est vu depuis ses deux salles : chacune n'est attirée que par son côté
+				dx, dy, length := delta(positions[a], positions[b])
+				force := length * length / ideal
+				moves[a] = Position{moves[a].X - dx/length*force, moves[a].Y - dy/length*force}
+			}
+		}
+		for _, room := range graph {
+			if room == entry || room == exit {
+				continue
+			}
+			move := moves[room]
+			length := math.Max(math.Hypot(move.X, move.Y), 1e-9)
+			step := math.Min(length, temperature)
+			p := positions[room]
+			// L'entrée reste à gauche et la sortie à droite de toutes les autres salles
+			x := math.Max(left, math.Min(p.X+move.X/length*step, right))
+			positions[room] = Position{X: x, Y: p.Y + move.Y/length*step}
+		}
+		temperature *= 0.99
+	}
+	return positions
+}
+
+// Vecteur de b vers a et sa longueur (jamais nulle).
+func delta(a, b Position) (dx, dy, length float64) {
+	dx, dy = a.X-b.X, a.Y-b.Y
+	return dx, dy, math.Max(math.Hypot(dx, dy), 1e-9)
+}
+
+// Arrondit une disposition sur une grille de caractères (cellsX colonnes et cellsY lignes par unité), décalée pour que
+// les plus petites coordonnées valent 0, en gardant l'étage de chaque salle. Sert à PrintColony.
+func Grid(positions Positions, cellsX, cellsY float64) map[*modules.Room]modules.Point {
+	minX, minY := math.Inf(1), math.Inf(1)
+	for _, p := range positions {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+	}
+	grid := make(map[*modules.Room]modules.Point, len(positions))
+	for room, p := range positions {
+		grid[room] = modules.Point{
+			X: int(math.Round((p.X - minX) * cellsX)),
+			Y: int(math.Round((p.Y - minY) * cellsY)),
+			Z: room.Coordinates.Z,
+		}
+	}
+	return grid
+}
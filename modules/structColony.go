@@ -1,10 +1,12 @@
 // Package modules defines core data structures for the lem-in project.
 package modules
 
-// Point represents a 2D coordinate (X, Y) for a room.
+// Point represents the coordinates of a room. Z is the floor of the room
+// and stays 0 for maps using only two coordinates.
 type Point struct {
 	X int
 	Y int
+	Z int
 }
 
 // Room represents a room in the colony, with its name, neighbours, and coordinates.
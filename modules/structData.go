@@ -3,7 +3,7 @@ package modules
 
 // Datas holds all parsed input data for the colony, including ants, rooms, links, and errors.
 type Datas struct {
-	NbAnts int      // Number of ants
+	NbAnts int64    // Number of ants
 	Start  string   // Start room definition
 	End    string   // End room definition
 	Rooms  []string // List of room definitions
@@ -0,0 +1,20 @@
+// Package modules defines core data structures for the lem-in project.
+package modules
+
+// Solution describes how the ants cross the colony: the paths used and how many ants take each of them.
+// Solvers whose ants do not follow fixed paths (they can wait or change their route) fill Moves instead.
+type Solution struct {
+	Paths       [][]*Room // Paths used, sorted by length
+	AntsPerPath []int64   // Number of ants sent in each path
+	Moves       [][]Move  // Explicit moves of each turn, used instead of Paths when not nil
+	Turns       int64     // Number of turns needed to move every ant to the end
+	SumArrival  int64     // Sum of the arrival turns of every ant
+	TotalMoves  int64     // Total number of ant moves
+	Optimal     bool      // True when the solver completed its search, false when it was interrupted
+}
+
+// Move is the move of one ant into a room during a turn.
+type Move struct {
+	Ant  int64
+	Room *Room
+}
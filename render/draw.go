@@ -0,0 +1,91 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Remplit un rectangle, découpé aux bords de l'image.
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, col color.RGBA) {
+	rect := image.Rect(x0, y0, x1, y1).Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetRGBA(x, y, col)
+		}
+	}
+}
+
+// Remplit un disque de centre (cx, cy).
+func fillCircle(img *image.RGBA, cx, cy, radius float64, col color.RGBA) {
+	for y := int(math.Floor(cy - radius)); y <= int(math.Ceil(cy+radius)); y++ {
+		for x := int(math.Floor(cx - radius)); x <= int(math.Ceil(cx+radius)); x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if dx*dx+dy*dy <= radius*radius && image.Pt(x, y).In(img.Bounds()) {
+				img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+// Trace un segment d'une épaisseur donnée, en posant un carré tous les demi-pixels.
+func drawLine(img *image.RGBA, x0, y0, x1, y1, thickness float64, col color.RGBA) {
+	steps := int(math.Ceil(2 * math.Hypot(x1-x0, y1-y0)))
+	half := thickness / 2
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		x, y := x0+t*(x1-x0), y0+t*(y1-y0)
+		fillRect(img, int(math.Round(x-half)), int(math.Round(y-half)), int(math.Round(x+half)), int(math.Round(y+half)), col)
+	}
+}
+
+// Police bitmap de 3 x 5 pixels : chaque glyphe est donné ligne par ligne, X pour un pixel allumé.
+// Les minuscules sont affichées en majuscules, les caractères absents par un pavé plein.
+var glyphs = map[rune]string{
+	'0': "XXX X.X X.X X.X XXX", '1': ".X. XX. .X. .X. XXX", '2': "XXX ..X XXX X.. XXX", '3': "XXX ..X XXX ..X XXX",
+	'4': "X.X X.X XXX ..X ..X", '5': "XXX X.. XXX ..X XXX", '6': "XXX X.. XXX X.X XXX", '7': "XXX ..X ..X .X. .X.",
+	'8': "XXX X.X XXX X.X XXX", '9': "XXX X.X XXX ..X XXX",
+	'A': ".X. X.X XXX X.X X.X", 'B': "XX. X.X XX. X.X XX.", 'C': ".XX X.. X.. X.. .XX", 'D': "XX. X.X X.X X.X XX.",
+	'E': "XXX X.. XX. X.. XXX", 'F': "XXX X.. XX. X.. X..", 'G': ".XX X.. X.X X.X .XX", 'H': "X.X X.X XXX X.X X.X",
+	'I': "XXX .X. .X. .X. XXX", 'J': "..X ..X ..X X.X .X.", 'K': "X.X X.X XX. X.X X.X", 'L': "X.. X.. X.. X.. XXX",
+	'M': "X.X XXX XXX X.X X.X", 'N': "XX. X.X X.X X.X X.X", 'O': ".X. X.X X.X X.X .X.", 'P': "XX. X.X XX. X.. X..",
+	'Q': ".X. X.X X.X XXX .XX", 'R': "XX. X.X XX. X.X X.X", 'S': ".XX X.. .X. ..X XX.", 'T': "XXX .X. .X. .X. .X.",
+	'U': "X.X X.X X.X X.X XXX", 'V': "X.X X.X X.X X.X .X.", 'W': "X.X X.X XXX XXX X.X", 'X': "X.X X.X .X. X.X X.X",
+	'Y': "X.X X.X .X. .X. .X.", 'Z': "XXX ..X .X. X.. XXX",
+	'-': "... ... XXX ... ...", '_': "... ... ... ... XXX", ':': "... .X. ... .X. ...", '/': "..X ..X .X. X.. X..",
+	'.': "... ... ... ... .X.", ' ': "... ... ... ... ...",
+}
+
+// Largeur et hauteur d'un caractère, espace compris, en pixels de la police (avant agrandissement)
+const (
+	glyphWidth  = 4
+	glyphHeight = 6
+)
+
+// Écrit un texte avec la police bitmap, agrandie scale fois, à partir du coin haut gauche (x, y).
+func drawText(img *image.RGBA, text string, x, y, scale int, col color.RGBA) {
+	for i, char := range []rune(text) {
+		glyph, exists := glyphs[unicode.ToUpper(char)]
+		if !exists {
+			glyph = "XXX XXX XXX XXX XXX"
+		}
+		for row, line := range strings.Fields(glyph) {
+			for column, pixel := range line {
+				if pixel == 'X' {
+					px, py := x+(i*glyphWidth+column)*scale, y+row*scale
+					fillRect(img, px, py, px+scale, py+scale, col)
+				}
+			}
+		}
+	}
+}
+
+// Largeur en pixels d'un texte écrit avec drawText.
+func textWidth(text string, scale int) int {
+	return len([]rune(text)) * glyphWidth * scale
+}
@@ -0,0 +1,263 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Durée pendant laquelle la dernière image reste affichée avant que l'animation ne recommence, en secondes
+const finalPause = 1
+
+// Écrit l'animation dans un fichier dont le format dépend du nom :
+// un GIF animé pour .gif, des images PNG numérotées pour un nom contenant un verbe de fmt (frames/%04d.png),
+// un PNG animé (APNG) pour les autres noms en .png ou .apng, une image SVG (animée si AnimatedSVG est vrai) pour .svg.
+func WriteFile(path string, a *Animation) error {
+	if strings.Contains(path, "%") {
+		return WriteFrames(path, a)
+	}
+	var write func(io.Writer) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gif":
+		write = func(w io.Writer) error { return WriteGIF(w, a) }
+	case ".png", ".apng":
+		write = func(w io.Writer) error { return WriteAPNG(w, a) }
+	case ".svg":
+		write = a.WriteSVG
+		if a.AnimatedSVG {
+			write = a.WriteAnimatedSVG
+		}
+	default:
+		return errors.New("Unknown animation format : " + path + " (use .gif, .png, .apng, .svg or a frame pattern like frames/%04d.png)")
+	}
+	// L'animation est écrite dans un fichier temporaire du même dossier, renommé seulement une fois complet :
+	// un encodage qui échoue ne laisse pas de fichier vide ou tronqué à la place du fichier demandé
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if err := file.Chmod(0o644); err != nil { // CreateTemp crée le fichier en 0600
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	err = write(writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// Écrit chaque image dans un fichier PNG dont le nom est pattern formaté avec le numéro de l'image (à partir de 0).
+func WriteFrames(pattern string, a *Animation) error {
+	for i := range a.FrameCount() {
+		file, err := os.Create(fmt.Sprintf(pattern, i))
+		if err != nil {
+			return err
+		}
+		err = png.Encode(file, a.Frame(i))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Écrit un GIF animé qui boucle indéfiniment. Les couleurs de l'animation forment la palette, sans tramage.
+// Comme pour WriteAPNG, chaque image est encodée seule par image/gif puis recopiée aussitôt : l'animation n'est
+// jamais gardée entière en mémoire (gif.EncodeAll demande toutes les images à la fois).
+func WriteGIF(w io.Writer, a *Animation) error {
+	palette := make(color.Palette, len(colors))
+	index := make(map[color.RGBA]uint8, len(colors))
+	for i, col := range colors {
+		palette[i] = col
+		index[col] = uint8(i)
+	}
+	delay := max(1, (100+a.FPS/2)/a.FPS) // En centièmes de seconde
+	frames := a.FrameCount()
+	paletted := image.NewPaletted(image.Rect(0, 0, a.Width, a.Height), palette)
+	for i := range frames {
+		frame := a.Frame(i)
+		for y := range a.Height {
+			for x := range a.Width {
+				paletted.SetColorIndex(x, y, index[frame.RGBAAt(x, y)])
+			}
+		}
+		var encoded bytes.Buffer
+		if err := gif.Encode(&encoded, paletted, nil); err != nil {
+			return err
+		}
+		header, block, err := splitGIF(encoded.Bytes())
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			// En-tête et palette de la première image, puis l'extension NETSCAPE2.0 : 0 boucle, soit indéfiniment
+			header = append(header, 0x21, 0xff, 11)
+			header = append(header, "NETSCAPE2.0"...)
+			header = append(header, 3, 1, 0, 0, 0)
+			if _, err := w.Write(header); err != nil {
+				return err
+			}
+		}
+		if i == frames-1 {
+			delay += 100 * finalPause
+		}
+		// Extension de contrôle : l'image suivante remplace celle-ci, qui reste affichée delay centièmes de seconde
+		control := []byte{0x21, 0xf9, 4, 0, byte(delay), byte(delay >> 8), 0, 0}
+		if _, err := w.Write(append(control, block...)); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0x3b}) // Fin du fichier
+	return err
+}
+
+// Sépare un GIF d'une seule image, sans extension de contrôle, en son en-tête (signature, description de l'écran et
+// palette globale) et son image (description, palette locale éventuelle et données compressées), sans le bloc final.
+func splitGIF(file []byte) (header, block []byte, err error) {
+	const screen = 13 // Signature et description de l'écran
+	if len(file) < screen+1 || !bytes.HasPrefix(file, []byte("GIF8")) || file[len(file)-1] != 0x3b {
+		return nil, nil, errors.New("Bad GIF file")
+	}
+	size := screen
+	if flags := file[10]; flags&0x80 != 0 {
+		size += 3 << (flags&7 + 1)
+	}
+	if len(file) <= size || file[size] != 0x2c {
+		return nil, nil, errors.New("Bad GIF file")
+	}
+	return file[:size:size], file[size : len(file)-1], nil
+}
+
+// Signature des fichiers PNG
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Écrit un PNG animé (APNG) qui boucle indéfiniment. Chaque image est encodée par image/png, dont on reprend les
+// données compressées (IDAT) : dans la première image telles quelles, dans les suivantes en fdAT, précédées du numéro
+// de séquence. Les images étant toutes opaques et de la même taille, image/png les encode toutes avec le même en-tête.
+func WriteAPNG(w io.Writer, a *Animation) error {
+	if _, err := w.Write(pngSignature); err != nil {
+		return err
+	}
+	var sequence uint32
+	frames := a.FrameCount()
+	for i := range frames {
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, a.Frame(i)); err != nil {
+			return err
+		}
+		chunks, err := readChunks(encoded.Bytes())
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			for _, c := range chunks {
+				if c.kind == "IHDR" {
+					if err := writeChunk(w, "IHDR", c.data); err != nil {
+						return err
+					}
+				}
+			}
+			// Nombre d'images et nombre de boucles (0 : indéfiniment)
+			if err := writeChunk(w, "acTL", binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(frames)), 0)); err != nil {
+				return err
+			}
+		}
+
+		// Délai de l'image en fraction de seconde : 1 / FPS, plus la pause finale pour la dernière image
+		delay := uint16(1)
+		if i == frames-1 {
+			delay += uint16(min(finalPause*a.FPS, 0xffff-1))
+		}
+		control := binary.BigEndian.AppendUint32(nil, sequence)
+		control = binary.BigEndian.AppendUint32(control, uint32(a.Width))
+		control = binary.BigEndian.AppendUint32(control, uint32(a.Height))
+		control = binary.BigEndian.AppendUint32(control, 0) // Position x
+		control = binary.BigEndian.AppendUint32(control, 0) // Position y
+		control = binary.BigEndian.AppendUint16(control, delay)
+		control = binary.BigEndian.AppendUint16(control, uint16(a.FPS))
+		control = append(control, 0, 0) // L'image suivante remplace entièrement celle-ci
+		if err := writeChunk(w, "fcTL", control); err != nil {
+			return err
+		}
+		sequence++
+
+		for _, c := range chunks {
+			if c.kind != "IDAT" {
+				continue
+			}
+			var err error
+			if i == 0 {
+				err = writeChunk(w, "IDAT", c.data)
+			} else {
+				err = writeChunk(w, "fdAT", append(binary.BigEndian.AppendUint32(nil, sequence), c.data...))
+				sequence++
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return writeChunk(w, "IEND", nil)
+}
+
+// Bloc d'un fichier PNG
+type chunk struct {
+	kind string
+	data []byte
+}
+
+// Découpe un fichier PNG en blocs.
+func readChunks(file []byte) ([]chunk, error) {
+	if !bytes.HasPrefix(file, pngSignature) {
+		return nil, errors.New("Bad PNG signature")
+	}
+	var chunks []chunk
+	for rest := file[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, errors.New("Truncated PNG chunk")
+		}
+		length := binary.BigEndian.Uint32(rest)
+		if uint64(len(rest)) < 12+uint64(length) {
+			return nil, errors.New("Truncated PNG chunk")
+		}
+		chunks = append(chunks, chunk{kind: string(rest[4:8]), data: rest[8 : 8+length]})
+		rest = rest[12+length:]
+	}
+	return chunks, nil
+}
+
+// Écrit un bloc PNG : longueur, type, données et CRC du type et des données.
+func writeChunk(w io.Writer, kind string, data []byte) error {
+	buffer := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	buffer = append(buffer, kind...)
+	buffer = append(buffer, data...)
+	buffer = binary.BigEndian.AppendUint32(buffer, crc32.ChecksumIEEE(buffer[4:]))
+	_, err := w.Write(buffer)
+	return err
+}
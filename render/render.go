@@ -0,0 +1,199 @@
+// Package render draws the animation of a solution without a display, using only the standard image packages.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"lem-in/layout"
+	"lem-in/modules"
+	"math"
+	"sort"
+)
+
+// Options règle la taille et la vitesse de l'animation.
+type Options struct {
+	Width, Height int     // Taille des images en pixels
+	FPS           int     // Images par seconde
+	Speed         float64 // Tours par seconde
+	Layout        string  // Disposition des salles (voir layout.Names)
+	AnimatedSVG   bool    // Pour un fichier .svg : anime les fourmis tour par tour au lieu d'une image fixe
+}
+
+// Réglages par défaut : la vitesse est celle du visualiseur.
+var DefaultOptions = Options{Width: 800, Height: 600, FPS: 25, Speed: 1.2, Layout: layout.AutoLayout}
+
+// Couleurs de l'animation. Elles forment aussi la palette des GIF, chaque pixel dessiné en fait donc partie.
+var (
+	background = color.RGBA{74, 52, 36, 255}
+	linkColor  = color.RGBA{124, 180, 50, 255}
+	roomColor  = color.RGBA{255, 255, 255, 255}
+	startColor = color.RGBA{0, 255, 0, 255}
+	endColor   = color.RGBA{255, 0, 0, 255}
+	antColor   = color.RGBA{20, 20, 20, 255}
+	badgeColor = color.RGBA{200, 60, 30, 255}
+	textColor  = color.RGBA{255, 255, 255, 255}
+)
+
+// Palette des GIF
+var colors = []color.RGBA{background, linkColor, roomColor, startColor, endColor, antColor, badgeColor, textColor}
+
+// Marge laissée autour de la colonie, en pixels pour une image de 600 pixels de côté
+const margin = 50
+
+// Animation calcule les images successives des mouvements d'une solution.
+type Animation struct {
+	Options
+	graph     []*modules.Room
+	positions map[*modules.Room]image.Point // Centre de chaque salle dans l'image
+	tracks    []Track
+	turns     int
+	unit      float64 // Taille des éléments dessinés (1 pour une image de 600 pixels de côté)
+}
+
+// Track liste les mouvements d'une fourmi : numéro (à partir de 0) du tour de chaque mouvement et salle atteinte.
+// Le visualiseur s'en sert aussi pour placer les fourmis.
+type Track struct {
+	Turns []int
+	Rooms []*modules.Room
+}
+
+// Prépare l'animation des mouvements de chaque tour. graph contient l'entrée en premier et la sortie en dernier.
+func NewAnimation(graph []*modules.Room, moves [][]modules.Move, opts Options) (*Animation, error) {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return nil, fmt.Errorf("Bad image size : %dx%d", opts.Width, opts.Height)
+	}
+	if opts.FPS <= 0 || opts.FPS > math.MaxUint16 {
+		return nil, fmt.Errorf("Bad number of frames per second : %d", opts.FPS)
+	}
+	if opts.Speed <= 0 {
+		return nil, errors.New("Bad speed : the number of turns per second must be positive")
+	}
+	positions, err := layout.Compute(opts.Layout, graph)
+	if err != nil {
+		return nil, err
+	}
+	a := &Animation{Options: opts, graph: graph, turns: len(moves), unit: float64(min(opts.Width, opts.Height)) / 600}
+	a.fit(positions)
+
+	byAnt := make(map[int64]int)
+	for turn, moves := range moves {
+		for _, move := range moves {
+			i, exists := byAnt[move.Ant]
+			if !exists {
+				i = len(a.tracks)
+				byAnt[move.Ant] = i
+				a.tracks = append(a.tracks, Track{})
+			}
+			a.tracks[i].Turns = append(a.tracks[i].Turns, turn)
+			a.tracks[i].Rooms = append(a.tracks[i].Rooms, move.Room)
+		}
+	}
+	return a, nil
+}
+
+// Place la colonie au centre de l'image en gardant ses proportions. Une colonie dont toutes les salles ont le même
+// X (ou le même Y) n'est agrandie que dans l'autre sens.
+func (a *Animation) fit(positions layout.Positions) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range positions {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	space := margin * a.unit
+	scale := math.Min((float64(a.Width)-2*space)/(maxX-minX), (float64(a.Height)-2*space)/(maxY-minY))
+	if math.IsInf(scale, 0) || math.IsNaN(scale) || scale <= 0 {
+		scale = 1
+	}
+	a.positions = make(map[*modules.Room]image.Point, len(positions))
+	for room, p := range positions {
+		a.positions[room] = image.Pt(
+			int(math.Round(float64(a.Width)/2+(p.X-(minX+maxX)/2)*scale)),
+			int(math.Round(float64(a.Height)/2+(p.Y-(minY+maxY)/2)*scale)),
+		)
+	}
+}
+
+// Renvoie le nombre d'images : FPS / Speed images par tour, plus la dernière qui montre toutes les fourmis arrivées.
+func (a *Animation) FrameCount() int {
+	return int(math.Ceil(float64(a.turns)*float64(a.FPS)/a.Speed)) + 1
+}
+
+// Dessine l'image numéro i : la colonie, les tunnels, les fourmis en mouvement, celles qui attendent dans une salle
+// (avec leur nombre) et le tour en cours.
+func (a *Animation) Frame(i int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, a.Width, a.Height))
+	fillRect(img, 0, 0, a.Width, a.Height, background)
+	size := func(pixels float64) float64 { return pixels * a.unit }
+	textScale := max(1, int(math.Round(size(2))))
+
+	for _, room := range a.graph {
+		for _, neighbour := range room.Neighbours {
+			from, to := a.positions[room], a.positions[neighbour]
+			drawLine(img, float64(from.X), float64(from.Y), float64(to.X), float64(to.Y), size(2), linkColor)
+		}
+	}
+	for r, room := range a.graph {
+		col := roomColor
+		if r == 0 {
+			col = startColor
+		} else if r == len(a.graph)-1 {
+			col = endColor
+		}
+		p, half := a.positions[room], int(size(8))
+		fillRect(img, p.X-half, p.Y-half, p.X+half+1, p.Y+half+1, col)
+		drawText(img, room.Name, p.X+half+int(size(3)), p.Y-half, textScale, textColor)
+	}
+
+	// Position dans l'animation : le tour en cours et l'avancement de ses mouvements
+	position := math.Min(float64(i)*a.Speed/float64(a.FPS), float64(a.turns))
+	turn, progress := int(math.Floor(position)), position-math.Floor(position)
+	if turn == a.turns {
+		turn, progress = a.turns-1, 1
+	}
+	standing := make(map[*modules.Room]int)
+	for _, t := range a.tracks {
+		from, to := t.At(turn, a.graph[0])
+		if from == to {
+			standing[from]++
+			continue
+		}
+		p, q := a.positions[from], a.positions[to]
+		x, y := float64(p.X)+progress*float64(q.X-p.X), float64(p.Y)+progress*float64(q.Y-p.Y)
+		fillCircle(img, x, y, size(5), antColor)
+	}
+	for _, room := range a.graph {
+		ants := standing[room]
+		if ants == 0 {
+			continue
+		}
+		p := a.positions[room]
+		fillCircle(img, float64(p.X), float64(p.Y), size(5), antColor)
+		label := fmt.Sprint(ants)
+		x, y := p.X+int(size(6)), p.Y-int(size(10))-glyphHeight*textScale
+		fillRect(img, x, y, x+textWidth(label, textScale)+textScale, y+glyphHeight*textScale, badgeColor)
+		drawText(img, label, x+textScale, y+textScale/2, textScale, textColor)
+	}
+
+	if a.turns > 0 {
+		caption := fmt.Sprintf("Turn %d / %d", turn+1, a.turns)
+		drawText(img, caption, int(size(10)), int(size(10)), textScale, textColor)
+	}
+	return img
+}
+
+// At renvoie la salle que la fourmi quitte et celle qu'elle atteint pendant un tour, la même salle si elle ne bouge
+// pas. Avant son premier mouvement, une fourmi attend dans l'entrée.
+func (t Track) At(turn int, start *modules.Room) (from, to *modules.Room) {
+	i := sort.SearchInts(t.Turns, turn)
+	from = start
+	if i > 0 {
+		from = t.Rooms[i-1]
+	}
+	if i < len(t.Turns) && t.Turns[i] == turn {
+		return from, t.Rooms[i]
+	}
+	return from, from
+}
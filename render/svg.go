@@ -0,0 +1,189 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"lem-in/modules"
+	"strings"
+)
+
+// Couleurs des chemins de la solution, dans l'ordre
+var pathColors = []color.RGBA{
+	{230, 25, 75, 255},
+	{60, 180, 255, 255},
+	{255, 225, 25, 255},
+	{245, 130, 48, 255},
+	{145, 30, 180, 255},
+	{70, 240, 240, 255},
+	{240, 50, 230, 255},
+	{250, 190, 212, 255},
+	{0, 128, 128, 255},
+	{220, 190, 255, 255},
+}
+
+// PathColor renvoie la couleur du chemin numéro i de la solution. Le visualiseur utilise les mêmes couleurs.
+func PathColor(i int) color.RGBA {
+	return pathColors[i%len(pathColors)]
+}
+
+// Path est un chemin suivi par les fourmis, de l'entrée à la sortie, avec le nombre de fourmis qui l'ont emprunté.
+type Path struct {
+	Rooms []*modules.Room
+	Ants  int
+}
+
+// Paths regroupe les fourmis selon la suite de salles qu'elles ont parcourue depuis start, dans l'ordre de leur
+// première fourmi.
+func Paths(tracks []Track, start *modules.Room) []*Path {
+	var paths []*Path
+	byKey := make(map[string]*Path)
+	for _, t := range tracks {
+		rooms := append([]*modules.Room{start}, t.Rooms...)
+		names := make([]string, len(rooms))
+		for i, room := range rooms {
+			names[i] = room.Name
+		}
+		key := strings.Join(names, "-")
+		path, exists := byKey[key]
+		if !exists {
+			path = &Path{Rooms: rooms}
+			byKey[key] = path
+			paths = append(paths, path)
+		}
+		path.Ants++
+	}
+	return paths
+}
+
+// Écrit une image SVG de la colonie et de la solution : les tunnels, chaque chemin dans sa couleur (plus épais quand
+// plus de fourmis l'empruntent), les salles avec leur nom, l'entrée et la sortie marquées, et la légende des chemins.
+func (a *Animation) WriteSVG(w io.Writer) error {
+	return a.writeSVG(w, false)
+}
+
+// Écrit la même image que WriteSVG, où les fourmis avancent tour par tour (animation SMIL, qui boucle indéfiniment
+// au rythme de Speed tours par seconde) avec le numéro du tour en cours.
+func (a *Animation) WriteAnimatedSVG(w io.Writer) error {
+	return a.writeSVG(w, true)
+}
+
+func (a *Animation) writeSVG(w io.Writer, animated bool) error {
+	writer := bufio.NewWriter(w)
+	size := func(pixels float64) float64 { return pixels * a.unit }
+	fmt.Fprintf(writer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="%.1f">`+"\n",
+		a.Width, a.Height, a.Width, a.Height, size(12))
+	fmt.Fprintf(writer, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(background))
+
+	// Tunnels, chacun une seule fois
+	fmt.Fprintf(writer, `<g stroke="%s" stroke-width="%.1f">`+"\n", hex(linkColor), size(2))
+	index := make(map[*modules.Room]int, len(a.graph))
+	for i, room := range a.graph {
+		index[room] = i
+	}
+	for i, room := range a.graph {
+		for _, neighbour := range room.Neighbours {
+			if index[neighbour] > i {
+				p, q := a.positions[room], a.positions[neighbour]
+				fmt.Fprintf(writer, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", p.X, p.Y, q.X, q.Y)
+			}
+		}
+	}
+	writer.WriteString("</g>\n")
+
+	paths := Paths(a.tracks, a.graph[0])
+	most := 1
+	for _, path := range paths {
+		most = max(most, path.Ants)
+	}
+	fmt.Fprintln(writer, `<g fill="none" stroke-linecap="round" stroke-linejoin="round" stroke-opacity="0.85">`)
+	for i, path := range paths {
+		points := make([]string, len(path.Rooms))
+		for j, room := range path.Rooms {
+			points[j] = fmt.Sprintf("%d,%d", a.positions[room].X, a.positions[room].Y)
+		}
+		fmt.Fprintf(writer, `<polyline points="%s" stroke="%s" stroke-width="%.1f"/>`+"\n",
+			strings.Join(points, " "), hex(PathColor(i)), size(2+6*float64(path.Ants)/float64(most)))
+	}
+	writer.WriteString("</g>\n")
+
+	half := size(8)
+	for i, room := range a.graph {
+		p := a.positions[room]
+		fill, marker := hex(roomColor), ""
+		if i == 0 {
+			fill, marker = hex(startColor), "start"
+		} else if i == len(a.graph)-1 {
+			fill, marker = hex(endColor), "end"
+		}
+		fmt.Fprintf(writer, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n",
+			float64(p.X)-half, float64(p.Y)-half, 2*half, 2*half, fill)
+		fmt.Fprintf(writer, `<text x="%.1f" y="%.1f" fill="%s">%s</text>`+"\n",
+			float64(p.X)+half+size(3), float64(p.Y)-size(2), hex(textColor), html.EscapeString(room.Name))
+		if marker != "" {
+			fmt.Fprintf(writer, `<text x="%.1f" y="%.1f" fill="%s" font-weight="bold">%s</text>`+"\n",
+				float64(p.X)+half+size(3), float64(p.Y)+size(12), fill, marker)
+		}
+	}
+
+	// Légende des chemins
+	for i, path := range paths {
+		y := size(10) + float64(i+2)*size(16)
+		fmt.Fprintf(writer, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n",
+			size(10), y-size(10), size(10), size(10), hex(PathColor(i)))
+		fmt.Fprintf(writer, `<text x="%.1f" y="%.1f" fill="%s">%d: length %d, %d ants</text>`+"\n",
+			size(24), y, hex(textColor), i+1, len(path.Rooms)-1, path.Ants)
+	}
+
+	if animated && a.turns > 0 {
+		a.writeSVGAnts(writer)
+	}
+	writer.WriteString("</svg>\n")
+	return writer.Flush()
+}
+
+// Écrit les fourmis animées et le numéro du tour. La position de chaque fourmi est donnée au début de l'animation
+// puis à la fin de chaque tour, et interpolée entre deux.
+func (a *Animation) writeSVGAnts(writer *bufio.Writer) {
+	duration := float64(a.turns) / a.Speed
+	keyTimes := make([]string, a.turns+1)
+	for turn := range keyTimes {
+		keyTimes[turn] = fmt.Sprintf("%.4f", float64(turn)/float64(a.turns))
+	}
+	times := strings.Join(keyTimes, ";")
+
+	fmt.Fprintf(writer, `<g fill="%s">`+"\n", hex(antColor))
+	start := a.positions[a.graph[0]]
+	for _, t := range a.tracks {
+		xs, ys := []string{fmt.Sprint(start.X)}, []string{fmt.Sprint(start.Y)}
+		for turn := range a.turns {
+			_, to := t.At(turn, a.graph[0])
+			xs, ys = append(xs, fmt.Sprint(a.positions[to].X)), append(ys, fmt.Sprint(a.positions[to].Y))
+		}
+		fmt.Fprintf(writer, `<circle cx="%d" cy="%d" r="%.1f">`, start.X, start.Y, a.unit*5)
+		for _, animation := range []struct {
+			attribute string
+			values    []string
+		}{{"cx", xs}, {"cy", ys}} {
+			fmt.Fprintf(writer, `<animate attributeName="%s" values="%s" keyTimes="%s" dur="%.3fs" repeatCount="indefinite"/>`,
+				animation.attribute, strings.Join(animation.values, ";"), times, duration)
+		}
+		writer.WriteString("</circle>\n")
+	}
+	writer.WriteString("</g>\n")
+
+	// Un texte par tour, visible uniquement pendant ce tour
+	for turn := range a.turns {
+		fmt.Fprintf(writer, `<text x="%.1f" y="%.1f" fill="%s" opacity="0">Turn %d / %d`,
+			a.unit*10, a.unit*20, hex(textColor), turn+1, a.turns)
+		fmt.Fprintf(writer, `<animate attributeName="opacity" values="0;1;0" keyTimes="0;%s;%s" calcMode="discrete" dur="%.3fs" repeatCount="indefinite"/></text>`+"\n",
+			keyTimes[turn], keyTimes[turn+1], duration)
+	}
+}
+
+// Écrit une couleur au format #rrggbb.
+func hex(col color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", col.R, col.G, col.B)
+}